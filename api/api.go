@@ -8,9 +8,17 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 )
 
+// DefaultAPIURL is the Alert Bingo checks API endpoint
+const DefaultAPIURL = "https://app.alert.bingo/api/v1/checks"
+
+// DefaultTimeout is the API client's timeout for a whole request, including reading the response
+const DefaultTimeout = 30 * time.Second
+
 // CheckPayload represents a single check to send to the API
 type CheckPayload struct {
 	Dashboard        string `json:"dashboard"`
@@ -23,6 +31,17 @@ type CheckPayload struct {
 	InactiveExpire   string `json:"inactive_expire,omitempty"`
 	InactiveEscalate string `json:"inactive_escalate,omitempty"`
 	Highlighted      string `json:"highlighted,omitempty"`
+
+	Measurement *Measurement `json:"-"` // data behind the check for message and value templates
+}
+
+// Measurement is the data behind a check, available to message and value
+// templates. It isn't sent to the API.
+type Measurement struct {
+	Raw        any            // the measured value, e.g. a percentage or time.Duration
+	Values     map[string]any // other measurements, e.g. "used" bytes
+	Thresholds map[string]any // limits the value is compared against, e.g. "warn": 95
+	Target     string         // the URL or file checked, if any
 }
 
 // Response represents the response from the API
@@ -38,15 +57,24 @@ type Client struct {
 	HTTPClient *http.Client
 }
 
-// NewClient creates a new API client
+// NewClient creates a new API client with DefaultTimeout. The proxy from the
+// environment (HTTPS_PROXY, NO_PROXY) is used unless UseProxy is called.
 func NewClient(apiURL, token string) *Client {
 	return &Client{
 		APIURL:     apiURL,
 		Token:      token,
-		HTTPClient: &http.Client{},
+		HTTPClient: &http.Client{Timeout: DefaultTimeout},
 	}
 }
 
+// UseProxy sends requests through the proxy returned by the given function
+// (see http.Transport.Proxy) instead of the proxy from the environment
+func (c *Client) UseProxy(proxy func(*http.Request) (*url.URL, error)) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	c.HTTPClient.Transport = transport
+}
+
 // SendChecks sends a slice of checks to the API
 func (c *Client) SendChecks(ctx context.Context, checks []CheckPayload) ([]Response, error) {
 	jsonData, err := json.Marshal(checks)
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 )
 
@@ -117,3 +118,26 @@ func TestClientSendChecksError(t *testing.T) {
 		t.Error("expected error for bad status code")
 	}
 }
+
+func TestClientUseProxy(t *testing.T) {
+	var gotURL string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURL = r.URL.String()
+		json.NewEncoder(w).Encode([]Response{{Status: "OK"}})
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	client := NewClient("http://api.example.test/checks", "test-token")
+	if client.HTTPClient.Timeout != DefaultTimeout {
+		t.Errorf("expected timeout %v, got %v", DefaultTimeout, client.HTTPClient.Timeout)
+	}
+	client.UseProxy(http.ProxyURL(proxyURL))
+
+	if _, err := client.SendChecks(context.Background(), []CheckPayload{}); err != nil {
+		t.Fatalf("SendChecks() error = %v", err)
+	}
+	if gotURL != "http://api.example.test/checks" {
+		t.Errorf("expected the proxy to receive the absolute API URL, got %q", gotURL)
+	}
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DefaultBatchSize is the number of checks sent per request by SendChecksBatched
+const DefaultBatchSize = 100
+
+// checkInput is a check read by ReadChecks. The alert level may be a number or
+// a name, and must be given rather than silently defaulting to ok.
+type checkInput struct {
+	CheckPayload
+	AlertLevel json.RawMessage `json:"alert_level"`
+}
+
+// ReadChecks reads checks from r, given either as a JSON array of check objects
+// or as a stream of check objects, e.g. one per line (NDJSON). Fields use the
+// API's names; alert_level may be 0, 1 or 2, or ok, warn or alert. Unknown
+// fields are rejected so typos don't go unnoticed.
+func ReadChecks(r io.Reader) ([]CheckPayload, error) {
+	br := bufio.NewReader(r)
+	first, err := peekNonSpace(br)
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checks: %w", err)
+	}
+
+	dec := json.NewDecoder(br)
+	dec.DisallowUnknownFields()
+
+	var inputs []checkInput
+	if first == '[' {
+		if err := dec.Decode(&inputs); err != nil {
+			return nil, fmt.Errorf("failed to parse checks: %w", err)
+		}
+		if dec.More() {
+			return nil, errors.New("failed to parse checks: unexpected data after JSON array")
+		}
+	} else {
+		for {
+			var input checkInput
+			err := dec.Decode(&input)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse check %d: %w", len(inputs)+1, err)
+			}
+			inputs = append(inputs, input)
+		}
+	}
+
+	checks := make([]CheckPayload, len(inputs))
+	for i, input := range inputs {
+		level, err := parseAlertLevelJSON(input.AlertLevel)
+		if err != nil {
+			return nil, fmt.Errorf("check %d: %w", i+1, err)
+		}
+		checks[i] = input.CheckPayload
+		checks[i].AlertLevel = level
+	}
+
+	return checks, nil
+}
+
+// peekNonSpace skips leading whitespace and returns the next byte without consuming it
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b, br.UnreadByte()
+	}
+}
+
+// parseAlertLevelJSON parses an alert level given as a number or a name
+func parseAlertLevelJSON(raw json.RawMessage) (int, error) {
+	if len(raw) == 0 {
+		return -1, errors.New("missing alert_level")
+	}
+
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		return ParseAlertLevel(name)
+	}
+
+	var level int
+	if err := json.Unmarshal(raw, &level); err != nil {
+		return -1, fmt.Errorf("invalid alert_level: %s", raw)
+	}
+	return level, nil
+}
+
+// Validate reports whether the check has the fields the API requires and a valid alert level
+func (p CheckPayload) Validate() error {
+	var missing []string
+	for _, field := range []struct{ name, value string }{
+		{"dashboard", p.Dashboard},
+		{"site", p.Site},
+		{"service", p.Service},
+		{"name", p.Name},
+	} {
+		if field.value == "" {
+			missing = append(missing, field.name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+
+	if p.AlertLevel < 0 || p.AlertLevel > 2 {
+		return fmt.Errorf("invalid alert_level: %d (must be 0, 1 or 2)", p.AlertLevel)
+	}
+
+	return nil
+}
+
+// ApplyDefaults fills each check's empty dashboard, site, service, inactive and
+// highlighted fields from defaults
+func ApplyDefaults(checks []CheckPayload, defaults CheckPayload) []CheckPayload {
+	for i := range checks {
+		c := &checks[i]
+		for _, f := range []struct {
+			dst *string
+			def string
+		}{
+			{&c.Dashboard, defaults.Dashboard},
+			{&c.Site, defaults.Site},
+			{&c.Service, defaults.Service},
+			{&c.InactiveExpire, defaults.InactiveExpire},
+			{&c.InactiveEscalate, defaults.InactiveEscalate},
+			{&c.Highlighted, defaults.Highlighted},
+		} {
+			if *f.dst == "" {
+				*f.dst = f.def
+			}
+		}
+	}
+	return checks
+}
+
+// ValidateChecks validates every check and returns an error listing each invalid one
+func ValidateChecks(checks []CheckPayload) error {
+	var errs []error
+	for i, check := range checks {
+		if err := check.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("check %d (%s): %w", i+1, check.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SendChecksBatched sends checks in batches of at most batchSize per request.
+// It stops at the first failed batch, returning the responses received so far.
+func (c *Client) SendChecksBatched(ctx context.Context, checks []CheckPayload, batchSize int) ([]Response, error) {
+	if batchSize < 1 {
+		batchSize = DefaultBatchSize
+	}
+
+	var responses []Response
+	for start := 0; start < len(checks); start += batchSize {
+		end := min(start+batchSize, len(checks))
+		batch, err := c.SendChecks(ctx, checks[start:end])
+		if err != nil {
+			return responses, fmt.Errorf("failed to send checks %d-%d of %d: %w", start+1, end, len(checks), err)
+		}
+		responses = append(responses, batch...)
+	}
+
+	return responses, nil
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReadChecks(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantLevels []int
+		wantErr    string
+	}{
+		{"array", `[{"name":"a","alert_level":0},{"name":"b","alert_level":"warn"}]`, []int{0, 1}, ""},
+		{"ndjson", "{\"name\":\"a\",\"alert_level\":2}\n\n{\"name\":\"b\",\"alert_level\":\"ok\"}\n", []int{2, 0}, ""},
+		{"empty", "  \n", nil, ""},
+		{"missing level", `{"name":"a"}`, nil, "check 1: missing alert_level"},
+		{"bad level", `{"name":"a","alert_level":"critical"}`, nil, "invalid alert level: critical"},
+		{"unknown field", `{"name":"a","alertlevel":1}`, nil, `unknown field "alertlevel"`},
+		{"bad ndjson line", "{\"name\":\"a\",\"alert_level\":0}\n{oops}\n", nil, "failed to parse check 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks, err := ReadChecks(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadChecks() error = %v", err)
+			}
+			if len(checks) != len(tt.wantLevels) {
+				t.Fatalf("expected %d checks, got %d", len(tt.wantLevels), len(checks))
+			}
+			for i, check := range checks {
+				if check.AlertLevel != tt.wantLevels[i] {
+					t.Errorf("checks[%d].AlertLevel = %d, want %d", i, check.AlertLevel, tt.wantLevels[i])
+				}
+			}
+		})
+	}
+}
+
+func TestApplyDefaultsAndValidate(t *testing.T) {
+	checks := []CheckPayload{
+		{Name: "a", Site: "override"},
+		{Name: "b", Service: "svc", AlertLevel: 3},
+		{Service: "svc"},
+	}
+	defaults := CheckPayload{Dashboard: "dash", Site: "site", InactiveExpire: "48h"}
+
+	checks = ApplyDefaults(checks, defaults)
+	if checks[0].Dashboard != "dash" || checks[0].Site != "override" || checks[0].InactiveExpire != "48h" {
+		t.Errorf("unexpected defaults applied: %+v", checks[0])
+	}
+
+	err := ValidateChecks(checks)
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	want := []string{
+		"check 1 (a): missing service",
+		"check 2 (b): invalid alert_level: 3",
+		"check 3 (): missing name",
+	}
+	for _, w := range want {
+		if !strings.Contains(err.Error(), w) {
+			t.Errorf("expected %q in %q", w, err.Error())
+		}
+	}
+}
+
+func TestClientSendChecksBatched(t *testing.T) {
+	var batches []int
+	failThird := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var checks []CheckPayload
+		json.NewDecoder(r.Body).Decode(&checks)
+		batches = append(batches, len(checks))
+		if failThird && len(batches) == 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		responses := make([]Response, len(checks))
+		for i := range responses {
+			responses[i].Status = "OK"
+		}
+		json.NewEncoder(w).Encode(responses)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token")
+	checks := make([]CheckPayload, 5)
+
+	responses, err := client.SendChecksBatched(context.Background(), checks, 2)
+	if err != nil {
+		t.Fatalf("SendChecksBatched() error = %v", err)
+	}
+	if len(responses) != 5 || len(batches) != 3 || batches[0] != 2 || batches[2] != 1 {
+		t.Errorf("expected 3 batches of 2, 2 and 1, got %v with %d responses", batches, len(responses))
+	}
+
+	// The third request fails, so only the first two batches are sent
+	batches, failThird = nil, true
+	responses, err = client.SendChecksBatched(context.Background(), make([]CheckPayload, 7), 3)
+	if err == nil || !strings.Contains(err.Error(), "failed to send checks 7-7 of 7") {
+		t.Errorf("expected error for the third batch, got %v", err)
+	}
+	if len(responses) != 6 {
+		t.Errorf("expected responses for the sent batches, got %d", len(responses))
+	}
+}
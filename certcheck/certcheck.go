@@ -3,7 +3,9 @@ package certcheck
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/alertbingo/alertbingo/api"
@@ -20,37 +22,94 @@ type Config struct {
 	InactiveEscalate string
 	Highlighted      string
 	Timeout          time.Duration
+	PKCS12Password   string      // password for PKCS#12 archives in file mode
+	Details          bool        // include a summary of the certificate metadata in the message
+	Pins             Pins        // expected fingerprints; a mismatch raises an alert
+	State            *State      // last seen certificates; a change raises a warning
+	SNI              string      // SNI and verification hostname; defaults to the URL host
+	ConnectTo        []ConnectTo // addresses to connect to instead of resolving the URL host
+	TLSConfig        *tls.Config // CAs, client certificates and verification settings; may be nil
+	OCSP             OCSPOptions
 }
 
-// Collect gathers certificate information for the given URLs and returns check payloads
+// Collect gathers certificate information for the given URLs and returns check payloads.
+// Targets using the file:// scheme are read from disk as with CollectFiles.
 func Collect(ctx context.Context, cfg Config, urls []string) []api.CheckPayload {
 	var checks []api.CheckPayload
 
 	for _, u := range urls {
-		check := checkCertificate(ctx, cfg, u)
-		checks = append(checks, check)
+		if ssl.IsFileTarget(u) {
+			checks = append(checks, CollectFiles(ctx, cfg, []string{u})...)
+			continue
+		}
+		for _, opts := range endpointOptions(cfg, u) {
+			check := checkCertificate(ctx, cfg, u, opts)
+			checks = append(checks, check)
+		}
 	}
 
 	return checks
 }
 
-func checkCertificate(ctx context.Context, cfg Config, rawURL string) api.CheckPayload {
-	certInfo, err := ssl.CheckCertificate(rawURL, cfg.Timeout)
+// CollectFiles checks the certificates in the given files (glob patterns allowed)
+// and returns a check payload for each leaf certificate found
+func CollectFiles(ctx context.Context, cfg Config, patterns []string) []api.CheckPayload {
+	var checks []api.CheckPayload
+
+	paths, err := ssl.ExpandPaths(patterns)
 	if err != nil {
-		return api.CheckPayload{
-			Dashboard:        cfg.Dashboard,
-			Site:             cfg.Site,
-			Service:          rawURL,
-			Name:             cfg.Name,
-			AlertLevel:       2, // alert
-			Value:            "Error",
-			Message:          err.Error(),
-			InactiveExpire:   cfg.InactiveExpire,
-			InactiveEscalate: cfg.InactiveEscalate,
-			Highlighted:      cfg.Highlighted,
+		return []api.CheckPayload{errorPayload(cfg, strings.Join(patterns, ", "), err)}
+	}
+
+	for _, path := range paths {
+		certInfos, err := ssl.CheckFile(path, cfg.PKCS12Password)
+		if err != nil {
+			checks = append(checks, errorPayload(cfg, path, err))
+			continue
+		}
+		for _, certInfo := range certInfos {
+			service := certInfo.Path
+			if certInfo.CommonName != "" {
+				service = fmt.Sprintf("%s (%s)", certInfo.Path, certInfo.CommonName)
+			}
+			checks = append(checks, expiryPayload(ctx, cfg, path, service, certInfo))
 		}
 	}
 
+	return checks
+}
+
+func checkCertificate(ctx context.Context, cfg Config, rawURL string, opts ssl.Options) api.CheckPayload {
+	certInfo, err := ssl.CheckCertificate(rawURL, opts)
+	if err != nil {
+		return errorPayload(cfg, endpointService(rawURL, opts.Address), err)
+	}
+
+	return expiryPayload(ctx, cfg, rawURL, endpointService(certInfo.Host, certInfo.Address), certInfo)
+}
+
+// errorPayload creates an alert CheckPayload for a target that could not be checked
+func errorPayload(cfg Config, service string, err error) api.CheckPayload {
+	return api.CheckPayload{
+		Dashboard:        cfg.Dashboard,
+		Site:             cfg.Site,
+		Service:          service,
+		Name:             cfg.Name,
+		AlertLevel:       2, // alert
+		Value:            "Error",
+		Message:          err.Error(),
+		InactiveExpire:   cfg.InactiveExpire,
+		InactiveEscalate: cfg.InactiveEscalate,
+		Highlighted:      cfg.Highlighted,
+	}
+}
+
+// expiryWarnDays is how many days before expiry a certificate warns
+const expiryWarnDays = 14
+
+// expiryPayload creates a CheckPayload from the certificate expiry thresholds, raising
+// the alert level if the certificate doesn't match its pins or has changed since last seen
+func expiryPayload(ctx context.Context, cfg Config, target, service string, certInfo *ssl.CertInfo) api.CheckPayload {
 	// Determine alert level based on days until expiry
 	alertLevel := 0
 	message := cfg.Message
@@ -61,7 +120,7 @@ func checkCertificate(ctx context.Context, cfg Config, rawURL string) api.CheckP
 		alertLevel = 2
 		value = "Expired"
 		message = appendAlertReason(cfg.Message, fmt.Sprintf("Certificate expired %d days ago", -certInfo.DaysUntil))
-	} else if certInfo.DaysUntil <= 14 {
+	} else if certInfo.DaysUntil <= expiryWarnDays {
 		// 2 weeks or less remaining
 		alertLevel = 1
 		value = fmt.Sprintf("%dd", certInfo.DaysUntil)
@@ -72,10 +131,46 @@ func checkCertificate(ctx context.Context, cfg Config, rawURL string) api.CheckP
 		value = fmt.Sprintf("%dd", certInfo.DaysUntil)
 	}
 
+	// Revocation can only be checked for certificates served over TLS
+	if certInfo.Path == "" {
+		level, reasons := checkRevocation(ctx, cfg, certInfo)
+		if level > 0 {
+			if level == 2 {
+				value = "Revoked"
+			}
+			alertLevel = max(alertLevel, level)
+			for _, reason := range reasons {
+				message = appendAlertReason(message, reason)
+			}
+		}
+	}
+
+	if pins := cfg.Pins.forTarget(target, certInfo.Host); len(pins) > 0 && !matchesPin(certInfo, pins) {
+		alertLevel = 2
+		message = appendAlertReason(message, fmt.Sprintf("Certificate does not match pinned fingerprint (got %s, SHA-256 %s)", certInfo.SPKIPin, certInfo.FingerprintSHA256))
+	}
+
+	if cfg.State != nil {
+		// Key by target and address, as pins are, so ports on one host are tracked
+		// separately. Bundles hold several leaf certificates, told apart by common name.
+		key := endpointService(target, certInfo.Address)
+		if certInfo.Path != "" {
+			key = service
+		}
+		if change := cfg.State.observe(key, certInfo); change != "" {
+			alertLevel = max(alertLevel, 1)
+			message = appendAlertReason(message, change)
+		}
+	}
+
+	if cfg.Details {
+		message = appendAlertReason(message, certInfo.Summary())
+	}
+
 	return api.CheckPayload{
 		Dashboard:        cfg.Dashboard,
 		Site:             cfg.Site,
-		Service:          certInfo.Host,
+		Service:          service,
 		Name:             cfg.Name,
 		AlertLevel:       alertLevel,
 		Value:            value,
@@ -83,6 +178,18 @@ func checkCertificate(ctx context.Context, cfg Config, rawURL string) api.CheckP
 		InactiveExpire:   cfg.InactiveExpire,
 		InactiveEscalate: cfg.InactiveEscalate,
 		Highlighted:      cfg.Highlighted,
+		Measurement: &api.Measurement{
+			Raw: certInfo.DaysUntil,
+			Values: map[string]any{
+				"expires_at":  certInfo.ExpiresAt,
+				"expires_in":  time.Until(certInfo.ExpiresAt),
+				"common_name": certInfo.CommonName,
+				"issuer":      certInfo.Issuer,
+				"sans":        certInfo.SANs,
+			},
+			Thresholds: map[string]any{"warn": expiryWarnDays, "alert": 0},
+			Target:     target,
+		},
 	}
 }
 
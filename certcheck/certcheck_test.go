@@ -2,8 +2,24 @@ package certcheck
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/alertbingo/alertbingo/tlsconfig"
+	"software.sslmate.com/src/go-pkcs12"
 )
 
 func TestCollect_ValidCertificate(t *testing.T) {
@@ -102,3 +118,241 @@ func TestAppendAlertReason(t *testing.T) {
 		}
 	}
 }
+
+// newTestCert creates a certificate signed by parent (or self-signed if parent is nil)
+func newTestCert(t *testing.T, cn string, isCA bool, notAfter time.Time, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return cert, key
+}
+
+func writePEM(t *testing.T, path string, certs ...*x509.Certificate) {
+	t.Helper()
+
+	var data []byte
+	for _, cert := range certs {
+		data = append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestCollectFiles_PEMBundle(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := newTestCert(t, "Test CA", true, time.Now().AddDate(5, 0, 0), nil, nil)
+	leaf, _ := newTestCert(t, "www.example.com", false, time.Now().AddDate(0, 0, 7), ca, caKey)
+	writePEM(t, filepath.Join(dir, "bundle.pem"), leaf, ca)
+
+	cfg := Config{Dashboard: "test-dash", Site: "test-site", Name: "cert-file"}
+	checks := CollectFiles(context.Background(), cfg, []string{filepath.Join(dir, "*.pem")})
+
+	// Only the leaf certificate should be reported
+	if len(checks) != 1 {
+		t.Fatalf("expected 1 check, got %d", len(checks))
+	}
+	check := checks[0]
+	expectedService := filepath.Join(dir, "bundle.pem") + " (www.example.com)"
+	if check.Service != expectedService {
+		t.Errorf("expected service %q, got %q", expectedService, check.Service)
+	}
+	// Expires in under 2 weeks
+	if check.AlertLevel != 1 {
+		t.Errorf("expected alert level 1, got %d", check.AlertLevel)
+	}
+}
+
+func TestCollectFiles_DER(t *testing.T) {
+	dir := t.TempDir()
+	cert, _ := newTestCert(t, "expired.example.com", false, time.Now().AddDate(0, 0, -3), nil, nil)
+	path := filepath.Join(dir, "cert.der")
+	if err := os.WriteFile(path, cert.Raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	checks := CollectFiles(context.Background(), Config{Name: "cert-file"}, []string{path})
+
+	if len(checks) != 1 {
+		t.Fatalf("expected 1 check, got %d", len(checks))
+	}
+	if checks[0].AlertLevel != 2 || checks[0].Value != "Expired" {
+		t.Errorf("expected expired alert, got level %d value %q", checks[0].AlertLevel, checks[0].Value)
+	}
+}
+
+func TestCollectFiles_PKCS12(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := newTestCert(t, "Test CA", true, time.Now().AddDate(5, 0, 0), nil, nil)
+	leaf, leafKey := newTestCert(t, "p12.example.com", false, time.Now().AddDate(1, 0, 0), ca, caKey)
+
+	data, err := pkcs12.Encode(rand.Reader, leafKey, leaf, []*x509.Certificate{ca}, "secret")
+	if err != nil {
+		t.Fatalf("failed to encode PKCS#12: %v", err)
+	}
+	path := filepath.Join(dir, "cert.p12")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Config{Name: "cert-file", PKCS12Password: "secret"}
+	checks := Collect(context.Background(), cfg, []string{"file://" + path})
+
+	if len(checks) != 1 {
+		t.Fatalf("expected 1 check, got %d", len(checks))
+	}
+	if checks[0].AlertLevel != 0 {
+		t.Errorf("expected alert level 0, got %d (%s)", checks[0].AlertLevel, checks[0].Message)
+	}
+
+	// Wrong password is reported as an error
+	cfg.PKCS12Password = "wrong"
+	checks = CollectFiles(context.Background(), cfg, []string{path})
+	if len(checks) != 1 || checks[0].Value != "Error" {
+		t.Errorf("expected error check for wrong password, got %+v", checks)
+	}
+}
+
+func TestCollectFiles_Missing(t *testing.T) {
+	checks := CollectFiles(context.Background(), Config{Name: "cert-file"}, []string{"/nonexistent/cert.pem"})
+
+	if len(checks) != 1 {
+		t.Fatalf("expected 1 check, got %d", len(checks))
+	}
+	if checks[0].AlertLevel != 2 || checks[0].Service != "/nonexistent/cert.pem" {
+		t.Errorf("expected alert for missing file, got %+v", checks[0])
+	}
+}
+
+func TestCollectFiles_Details(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := newTestCert(t, "Test CA", true, time.Now().AddDate(5, 0, 0), nil, nil)
+	leaf, _ := newTestCert(t, "www.example.com", false, time.Now().AddDate(1, 0, 0), ca, caKey)
+	path := filepath.Join(dir, "leaf.pem")
+	writePEM(t, path, leaf)
+
+	cfg := Config{Name: "cert-file", Message: "prefix", Details: true}
+	checks := CollectFiles(context.Background(), cfg, []string{path})
+
+	if len(checks) != 1 {
+		t.Fatalf("expected 1 check, got %d", len(checks))
+	}
+	msg := checks[0].Message
+	for _, want := range []string{"prefix - ", "Subject: CN=www.example.com", "Issuer: CN=Test CA", "SHA-256: ", "Key: ECDSA 256", "Signature: ECDSA-SHA256"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("expected message to contain %q, got %q", want, msg)
+		}
+	}
+}
+
+func TestCollect_CustomCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writePEM(t, caFile, server.Certificate())
+
+	tests := []struct {
+		name      string
+		opts      tlsconfig.Options
+		wantLevel int
+	}{
+		{"system roots", tlsconfig.Options{}, 2},
+		{"custom CA", tlsconfig.Options{CAFile: caFile}, 0},
+		{"insecure skip verify", tlsconfig.Options{InsecureSkipVerify: true}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := tt.opts.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := Config{Name: "ssl", Timeout: 5 * time.Second, TLSConfig: tlsConfig}
+			checks := Collect(context.Background(), cfg, []string{server.URL})
+
+			if len(checks) != 1 {
+				t.Fatalf("expected 1 check, got %d", len(checks))
+			}
+			if checks[0].AlertLevel != tt.wantLevel {
+				t.Errorf("expected alert level %d, got %d (%s)", tt.wantLevel, checks[0].AlertLevel, checks[0].Message)
+			}
+			if tt.wantLevel == 0 && checks[0].Service != "127.0.0.1" {
+				t.Errorf("expected service '127.0.0.1', got %q", checks[0].Service)
+			}
+		})
+	}
+}
+
+func TestCollect_ClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	clientCA, clientCAKey := newTestCert(t, "Client CA", true, time.Now().AddDate(1, 0, 0), nil, nil)
+	client, clientKey := newTestCert(t, "client", false, time.Now().AddDate(1, 0, 0), clientCA, clientCAKey)
+
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	writePEM(t, certFile, client)
+	keyDER, err := x509.MarshalECPrivateKey(clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCA)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	// With TLS 1.3 the client handshake completes before the server rejects it, so
+	// limit the server to TLS 1.2 for a missing client certificate to fail the check
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs, MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	for _, withCert := range []bool{false, true} {
+		opts := tlsconfig.Options{InsecureSkipVerify: true}
+		if withCert {
+			opts.ClientCert, opts.ClientKey = certFile, keyFile
+		}
+		tlsConfig, err := opts.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cfg := Config{Name: "ssl", Timeout: 5 * time.Second, TLSConfig: tlsConfig}
+		checks := Collect(context.Background(), cfg, []string{server.URL})
+
+		wantLevel := 2
+		if withCert {
+			wantLevel = 0
+		}
+		if checks[0].AlertLevel != wantLevel {
+			t.Errorf("client cert %v: expected alert level %d, got %d (%s)", withCert, wantLevel, checks[0].AlertLevel, checks[0].Message)
+		}
+	}
+}
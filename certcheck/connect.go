@@ -0,0 +1,69 @@
+package certcheck
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alertbingo/alertbingo/certcheck/ssl"
+	"github.com/alertbingo/alertbingo/internal/checkutil"
+)
+
+// ConnectTo overrides the address connected to for a host and port, so that
+// individual backends can be checked while verifying the intended hostname.
+// An empty Host or Port matches any host or port.
+type ConnectTo struct {
+	Host    string
+	Port    string
+	Address string
+}
+
+// ParseConnectTo parses a host:port:address specification, as urlcheck.ParseResolve
+// does. The address is an IP address or hostname; IPv6 addresses may be given in
+// brackets (e.g. example.com:443:[2001:db8::1]).
+func ParseConnectTo(spec string) (ConnectTo, error) {
+	host, port, address, err := checkutil.ParseHostOverride(spec)
+	if err != nil {
+		return ConnectTo{}, fmt.Errorf("invalid connect-to: %s (%w)", spec, err)
+	}
+	return ConnectTo{Host: host, Port: port, Address: address}, nil
+}
+
+// matches reports whether the override applies to the given host and port
+func (c ConnectTo) matches(host, port string) bool {
+	return (c.Host == "" || strings.EqualFold(c.Host, host)) && (c.Port == "" || c.Port == port)
+}
+
+// endpointOptions returns the connection options for each endpoint a URL should be
+// checked on: one per matching connect-to override, or the URL host itself
+func endpointOptions(cfg Config, rawURL string) []ssl.Options {
+	base := ssl.Options{Timeout: cfg.Timeout, ServerName: cfg.SNI, TLSConfig: cfg.TLSConfig}
+
+	host, port, err := ssl.ParseTarget(rawURL)
+	if err != nil {
+		// Let the check itself report the invalid URL
+		return []ssl.Options{base}
+	}
+
+	var options []ssl.Options
+	for _, c := range cfg.ConnectTo {
+		if c.matches(host, port) {
+			opts := base
+			opts.Address = c.Address
+			options = append(options, opts)
+		}
+	}
+	if len(options) == 0 {
+		options = append(options, base)
+	}
+
+	return options
+}
+
+// endpointService identifies an endpoint in check payloads, including the address
+// connected to when it differs from the hostname
+func endpointService(host, address string) string {
+	if address == "" {
+		return host
+	}
+	return fmt.Sprintf("%s (%s)", host, address)
+}
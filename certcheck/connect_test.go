@@ -0,0 +1,85 @@
+package certcheck
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseConnectTo(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    ConnectTo
+		wantErr bool
+	}{
+		{"example.com:443:10.0.0.1", ConnectTo{"example.com", "443", "10.0.0.1"}, false},
+		{"example.com:443:[2001:db8::1]", ConnectTo{"example.com", "443", "2001:db8::1"}, false},
+		{"::10.0.0.1", ConnectTo{"", "", "10.0.0.1"}, false},
+		{"example.com:443:backend.internal", ConnectTo{"example.com", "443", "backend.internal"}, false},
+		{"example.com:443", ConnectTo{}, true},
+		{"example.com:443:", ConnectTo{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseConnectTo(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseConnectTo(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseConnectTo(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEndpointOptions(t *testing.T) {
+	cfg := Config{
+		Timeout: time.Second,
+		SNI:     "www.example.com",
+		ConnectTo: []ConnectTo{
+			{Host: "lb.example.com", Port: "443", Address: "10.0.0.1"},
+			{Host: "lb.example.com", Port: "443", Address: "10.0.0.2"},
+			{Host: "lb.example.com", Port: "8443", Address: "10.0.0.3"},
+		},
+	}
+
+	opts := endpointOptions(cfg, "https://lb.example.com")
+	if len(opts) != 2 {
+		t.Fatalf("expected 2 endpoints, got %d", len(opts))
+	}
+	if opts[0].Address != "10.0.0.1" || opts[1].Address != "10.0.0.2" {
+		t.Errorf("unexpected addresses: %+v", opts)
+	}
+	if opts[0].ServerName != "www.example.com" {
+		t.Errorf("expected SNI to be carried over, got %q", opts[0].ServerName)
+	}
+
+	opts = endpointOptions(cfg, "https://other.example.com")
+	if len(opts) != 1 || opts[0].Address != "" {
+		t.Errorf("expected a single endpoint without override, got %+v", opts)
+	}
+}
+
+func TestCollect_ConnectToService(t *testing.T) {
+	cfg := Config{
+		Name:    "ssl",
+		Timeout: time.Second,
+		ConnectTo: []ConnectTo{
+			{Host: "www.example.com", Port: "1", Address: "127.0.0.1"},
+		},
+	}
+
+	checks := Collect(context.Background(), cfg, []string{"https://www.example.com:1"})
+
+	if len(checks) != 1 {
+		t.Fatalf("expected 1 check, got %d", len(checks))
+	}
+	// Nothing listens on port 1, but the check identifies both host and address
+	if checks[0].Service != "https://www.example.com:1 (127.0.0.1)" {
+		t.Errorf("unexpected service %q", checks[0].Service)
+	}
+	if checks[0].AlertLevel != 2 {
+		t.Errorf("expected alert level 2, got %d", checks[0].AlertLevel)
+	}
+}
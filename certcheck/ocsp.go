@@ -0,0 +1,88 @@
+package certcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alertbingo/alertbingo/certcheck/ssl"
+)
+
+// OCSPOptions configures revocation checking. Stapled OCSP responses are always
+// inspected; a revoked status raises an alert.
+type OCSPOptions struct {
+	Query         bool         // also query the OCSP responder from the certificate's AIA extension
+	ResponderURL  string       // responder to query instead of the one in the certificate
+	RequireStaple bool         // warn when no valid OCSP response is stapled
+	Client        *http.Client // queries the responder; nil for NewOCSPClient(cfg)
+}
+
+// NewOCSPClient returns a client for OCSP queries that uses the check's timeout
+// and TLS settings and the proxy from the environment
+func NewOCSPClient(cfg Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.TLSConfig != nil {
+		transport.TLSClientConfig = cfg.TLSConfig.Clone()
+	}
+	return &http.Client{Timeout: cfg.Timeout, Transport: transport}
+}
+
+// checkRevocation returns the alert level and reasons from the certificate's
+// stapled OCSP response and, if configured, the OCSP responder
+func checkRevocation(ctx context.Context, cfg Config, certInfo *ssl.CertInfo) (int, []string) {
+	alertLevel := 0
+	var reasons []string
+
+	staple, err := ssl.StapledStatus(certInfo)
+	switch {
+	case err != nil:
+		alertLevel = 1
+		reasons = append(reasons, err.Error())
+	case staple != nil:
+		level, reason := ocspStatusReason(staple, "OCSP staple")
+		alertLevel = max(alertLevel, level)
+		if reason != "" {
+			reasons = append(reasons, reason)
+		}
+		if staple.IsExpired() && (cfg.OCSP.RequireStaple || certInfo.MustStaple) {
+			alertLevel = max(alertLevel, 1)
+			reasons = append(reasons, fmt.Sprintf("Stapled OCSP response expired at %s", staple.NextUpdate.UTC().Format(time.RFC3339)))
+		}
+	case cfg.OCSP.RequireStaple || certInfo.MustStaple:
+		alertLevel = 1
+		reasons = append(reasons, "No OCSP response stapled")
+	}
+
+	if cfg.OCSP.Query {
+		client := cfg.OCSP.Client
+		if client == nil {
+			client = NewOCSPClient(cfg)
+		}
+		status, err := ssl.QueryOCSP(ctx, client, certInfo, cfg.OCSP.ResponderURL)
+		if err != nil {
+			alertLevel = max(alertLevel, 1)
+			reasons = append(reasons, err.Error())
+		} else {
+			level, reason := ocspStatusReason(status, "OCSP responder")
+			alertLevel = max(alertLevel, level)
+			if reason != "" {
+				reasons = append(reasons, reason)
+			}
+		}
+	}
+
+	return alertLevel, reasons
+}
+
+// ocspStatusReason maps an OCSP status to an alert level and reason
+func ocspStatusReason(status *ssl.OCSPStatus, source string) (int, string) {
+	switch status.Status {
+	case "revoked":
+		return 2, fmt.Sprintf("Certificate revoked at %s (%s)", status.RevokedAt.UTC().Format(time.RFC3339), source)
+	case "unknown":
+		return 1, fmt.Sprintf("Certificate status unknown (%s)", source)
+	default:
+		return 0, ""
+	}
+}
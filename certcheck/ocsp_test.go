@@ -0,0 +1,203 @@
+package certcheck
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alertbingo/alertbingo/tlsconfig"
+	"golang.org/x/crypto/ocsp"
+)
+
+// ocspFixture is a CA and a server certificate for 127.0.0.1 issued by it
+type ocspFixture struct {
+	ca      *x509.Certificate
+	caKey   *ecdsa.PrivateKey
+	leaf    *x509.Certificate
+	leafKey *ecdsa.PrivateKey
+	caFile  string
+}
+
+func newOCSPFixture(t *testing.T) *ocspFixture {
+	t.Helper()
+
+	ca, caKey := newTestCert(t, "OCSP Test CA", true, time.Now().AddDate(1, 0, 0), nil, nil)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+		OCSPServer:   []string{"http://127.0.0.1:1/unreachable"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writePEM(t, caFile, ca)
+
+	return &ocspFixture{ca: ca, caKey: caKey, leaf: leaf, leafKey: leafKey, caFile: caFile}
+}
+
+// response creates a signed OCSP response for the leaf certificate
+func (f *ocspFixture) response(t *testing.T, status int, nextUpdate time.Time) []byte {
+	t.Helper()
+
+	resp, err := ocsp.CreateResponse(f.ca, f.ca, ocsp.Response{
+		Status:       status,
+		SerialNumber: f.leaf.SerialNumber,
+		ThisUpdate:   time.Now().Add(-time.Hour),
+		NextUpdate:   nextUpdate,
+		RevokedAt:    time.Now().Add(-time.Minute),
+	}, f.caKey)
+	if err != nil {
+		t.Fatalf("failed to create OCSP response: %v", err)
+	}
+	return resp
+}
+
+// server starts a TLS server presenting the leaf certificate with an optional staple
+func (f *ocspFixture) server(t *testing.T, staple []byte) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{
+			Certificate: [][]byte{f.leaf.Raw, f.ca.Raw},
+			PrivateKey:  f.leafKey,
+			OCSPStaple:  staple,
+		}},
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (f *ocspFixture) config(t *testing.T) Config {
+	t.Helper()
+
+	tlsConfig, err := tlsconfig.Options{CAFile: f.caFile}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Config{Name: "ssl", Timeout: 5 * time.Second, TLSConfig: tlsConfig}
+}
+
+func TestCollect_OCSPStaple(t *testing.T) {
+	f := newOCSPFixture(t)
+
+	tests := []struct {
+		name          string
+		staple        []byte
+		requireStaple bool
+		wantLevel     int
+		wantMessage   string
+	}{
+		{"good staple", f.response(t, ocsp.Good, time.Now().Add(time.Hour)), true, 0, ""},
+		{"revoked staple", f.response(t, ocsp.Revoked, time.Now().Add(time.Hour)), false, 2, "Certificate revoked"},
+		{"expired staple", f.response(t, ocsp.Good, time.Now().Add(-time.Minute)), true, 1, "Stapled OCSP response expired"},
+		{"expired staple not required", f.response(t, ocsp.Good, time.Now().Add(-time.Minute)), false, 0, ""},
+		{"missing staple", nil, true, 1, "No OCSP response stapled"},
+		{"missing staple not required", nil, false, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := f.server(t, tt.staple)
+			cfg := f.config(t)
+			cfg.OCSP.RequireStaple = tt.requireStaple
+
+			checks := Collect(context.Background(), cfg, []string{server.URL})
+
+			if len(checks) != 1 {
+				t.Fatalf("expected 1 check, got %d", len(checks))
+			}
+			if checks[0].AlertLevel != tt.wantLevel {
+				t.Errorf("expected alert level %d, got %d (%s)", tt.wantLevel, checks[0].AlertLevel, checks[0].Message)
+			}
+			if !strings.Contains(checks[0].Message, tt.wantMessage) {
+				t.Errorf("expected message to contain %q, got %q", tt.wantMessage, checks[0].Message)
+			}
+		})
+	}
+}
+
+func TestCollect_OCSPResponder(t *testing.T) {
+	f := newOCSPFixture(t)
+	server := f.server(t, nil)
+
+	status := ocsp.Good
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if _, err := ocsp.ParseRequest(body); err != nil {
+			t.Errorf("invalid OCSP request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/ocsp-response")
+		w.Write(f.response(t, status, time.Now().Add(time.Hour)))
+	}))
+	defer responder.Close()
+
+	cfg := f.config(t)
+	cfg.OCSP = OCSPOptions{Query: true, ResponderURL: responder.URL}
+
+	checks := Collect(context.Background(), cfg, []string{server.URL})
+	if checks[0].AlertLevel != 0 {
+		t.Errorf("expected alert level 0 for good status, got %d (%s)", checks[0].AlertLevel, checks[0].Message)
+	}
+
+	status = ocsp.Revoked
+	checks = Collect(context.Background(), cfg, []string{server.URL})
+	if checks[0].AlertLevel != 2 || checks[0].Value != "Revoked" {
+		t.Errorf("expected revoked alert, got level %d value %q (%s)", checks[0].AlertLevel, checks[0].Value, checks[0].Message)
+	}
+
+	// A configured client, e.g. with a proxy, is used for the query
+	var queried bool
+	cfg.OCSP.Client = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		queried = true
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+	Collect(context.Background(), cfg, []string{server.URL})
+	if !queried {
+		t.Error("expected the query to use the configured client")
+	}
+	cfg.OCSP.Client = nil
+
+	// The responder from the certificate's AIA extension is unreachable
+	cfg.OCSP.ResponderURL = ""
+	checks = Collect(context.Background(), cfg, []string{server.URL})
+	if checks[0].AlertLevel != 1 || !strings.Contains(checks[0].Message, "OCSP request failed") {
+		t.Errorf("expected warning for unreachable responder, got level %d (%s)", checks[0].AlertLevel, checks[0].Message)
+	}
+}
+
+// roundTripFunc is an http.RoundTripper implemented by a function
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
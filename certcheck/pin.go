@@ -0,0 +1,159 @@
+package certcheck
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alertbingo/alertbingo/certcheck/ssl"
+	"github.com/alertbingo/alertbingo/internal/atomicfile"
+)
+
+// Pins maps targets (URL, host or file path) to their expected certificate fingerprints.
+// Pins stored under the empty key apply to every target.
+type Pins map[string][]string
+
+// ParsePins parses pin specifications of the form [target=]pin, where pin is either an
+// SPKI pin (sha256/<base64>) or a hex SHA-256 certificate fingerprint
+func ParsePins(specs []string) (Pins, error) {
+	pins := make(Pins)
+
+	for _, spec := range specs {
+		target, pin := "", strings.TrimSpace(spec)
+		if !validPin(pin) {
+			// The pin follows the last "=" in the spec, as hex fingerprints have none
+			// and SPKI pins only as base64 padding after "sha256/"; the target (e.g. a
+			// URL with a query string) may have any number
+			i := strings.LastIndex(spec, "=sha256/")
+			if i < 0 {
+				i = strings.LastIndex(spec, "=")
+			}
+			if i > 0 {
+				target, pin = spec[:i], strings.TrimSpace(spec[i+1:])
+			}
+		}
+
+		if !validPin(pin) {
+			return nil, fmt.Errorf("invalid pin: %s (must be sha256/<base64> or a hex SHA-256 fingerprint)", spec)
+		}
+		pins[target] = append(pins[target], pin)
+	}
+
+	return pins, nil
+}
+
+// validPin reports whether pin is an SPKI pin or a hex fingerprint of a SHA-256 digest
+func validPin(pin string) bool {
+	var digest []byte
+	var err error
+	if encoded, ok := strings.CutPrefix(pin, "sha256/"); ok {
+		digest, err = base64.StdEncoding.DecodeString(encoded)
+	} else {
+		digest, err = hex.DecodeString(normalizeFingerprint(pin))
+	}
+	return err == nil && len(digest) == sha256.Size
+}
+
+// forTarget returns the pins configured for a target and its host
+func (p Pins) forTarget(target, host string) []string {
+	var pins []string
+	pins = append(pins, p[""]...)
+	pins = append(pins, p[target]...)
+	if host != "" && host != target {
+		pins = append(pins, p[host]...)
+	}
+	return pins
+}
+
+// matchesPin reports whether the certificate matches any of the given pins
+func matchesPin(certInfo *ssl.CertInfo, pins []string) bool {
+	for _, pin := range pins {
+		if strings.HasPrefix(pin, "sha256/") {
+			if pin == certInfo.SPKIPin {
+				return true
+			}
+			continue
+		}
+		if normalizeFingerprint(pin) == normalizeFingerprint(certInfo.FingerprintSHA256) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeFingerprint strips separators and lower-cases a hex fingerprint
+func normalizeFingerprint(fingerprint string) string {
+	fingerprint = strings.ReplaceAll(fingerprint, ":", "")
+	return strings.ToLower(fingerprint)
+}
+
+// seenCert records the certificate last seen for a target
+type seenCert struct {
+	Fingerprint string    `json:"fingerprint"`
+	Issuer      string    `json:"issuer"`
+	Serial      string    `json:"serial"`
+	SeenAt      time.Time `json:"seen_at"`
+}
+
+// State persists the last seen certificate for each target so changes can be detected
+type State struct {
+	path  string
+	certs map[string]seenCert
+}
+
+// LoadState reads the state file at path. A missing file results in an empty state.
+func LoadState(path string) (*State, error) {
+	state := &State{path: path, certs: make(map[string]seenCert)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &state.certs); err != nil {
+		return nil, fmt.Errorf("failed to parse state file: %w", err)
+	}
+
+	return state, nil
+}
+
+// Save writes the state back to its file
+func (s *State) Save() error {
+	data, err := json.MarshalIndent(s.certs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	if err := atomicfile.Write(s.path, data); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	return nil
+}
+
+// observe records the certificate for a target and returns a description of the
+// change if a different certificate was previously seen
+func (s *State) observe(target string, certInfo *ssl.CertInfo) string {
+	previous, seen := s.certs[target]
+	s.certs[target] = seenCert{
+		Fingerprint: certInfo.FingerprintSHA256,
+		Issuer:      certInfo.Issuer,
+		Serial:      certInfo.SerialNumber,
+		SeenAt:      time.Now().UTC(),
+	}
+
+	if !seen || previous.Fingerprint == certInfo.FingerprintSHA256 {
+		return ""
+	}
+
+	return fmt.Sprintf("Certificate changed: was issuer %s serial %s, now issuer %s serial %s",
+		previous.Issuer, previous.Serial, certInfo.Issuer, certInfo.SerialNumber)
+}
@@ -0,0 +1,166 @@
+package certcheck
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePins(t *testing.T) {
+	spki := "sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="
+	fingerprint := strings.Repeat("ab", 32)
+
+	pins, err := ParsePins([]string{spki, "https://example.com=" + fingerprint, "example.org=" + spki})
+	if err != nil {
+		t.Fatalf("ParsePins() error = %v", err)
+	}
+	if len(pins[""]) != 1 || pins[""][0] != spki {
+		t.Errorf("expected global SPKI pin, got %v", pins[""])
+	}
+	if len(pins["https://example.com"]) != 1 || pins["https://example.com"][0] != fingerprint {
+		t.Errorf("expected fingerprint pin for URL, got %v", pins["https://example.com"])
+	}
+	if got := pins.forTarget("https://example.org", "example.org"); len(got) != 2 {
+		t.Errorf("expected global and host pins, got %v", got)
+	}
+
+	// Base64 padding and "=" in a target's query string are not separators
+	pins, err = ParsePins([]string{"https://example.com/?a=b=" + spki, "https://example.com/?a=b=" + fingerprint})
+	if err != nil {
+		t.Fatalf("ParsePins() error = %v", err)
+	}
+	if got := pins["https://example.com/?a=b"]; len(got) != 2 || got[0] != spki || got[1] != fingerprint {
+		t.Errorf("expected pins for the URL with a query string, got %v", pins)
+	}
+
+	for _, invalid := range []string{
+		"not-a-pin",
+		strings.Repeat("zz", 32),            // 64 characters, not hex
+		"sha256/not-base64!",                // not base64
+		"sha256/" + strings.Repeat("A", 20), // not a SHA-256 digest
+	} {
+		if _, err := ParsePins([]string{invalid}); err == nil {
+			t.Errorf("expected error for invalid pin %q", invalid)
+		}
+	}
+}
+
+func TestCollectFiles_Pins(t *testing.T) {
+	dir := t.TempDir()
+	cert, _ := newTestCert(t, "pinned.example.com", false, time.Now().AddDate(1, 0, 0), nil, nil)
+	path := filepath.Join(dir, "cert.pem")
+	writePEM(t, path, cert)
+
+	sum := sha256.Sum256(cert.Raw)
+	tests := []struct {
+		name      string
+		pin       string
+		wantLevel int
+	}{
+		{"matching fingerprint", path + "=" + hex.EncodeToString(sum[:]), 0},
+		{"mismatched fingerprint", path + "=" + strings.Repeat("00", 32), 2},
+		{"pin for other target", "other.pem=" + strings.Repeat("00", 32), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pins, err := ParsePins([]string{tt.pin})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			checks := CollectFiles(context.Background(), Config{Name: "pin", Pins: pins}, []string{path})
+			if len(checks) != 1 {
+				t.Fatalf("expected 1 check, got %d", len(checks))
+			}
+			if checks[0].AlertLevel != tt.wantLevel {
+				t.Errorf("expected alert level %d, got %d (%s)", tt.wantLevel, checks[0].AlertLevel, checks[0].Message)
+			}
+		})
+	}
+}
+
+func TestCollectFiles_StateChange(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cert.pem")
+	statePath := filepath.Join(dir, "state.json")
+
+	first, _ := newTestCert(t, "www.example.com", false, time.Now().AddDate(1, 0, 0), nil, nil)
+	second, _ := newTestCert(t, "www.example.com", false, time.Now().AddDate(1, 0, 0), nil, nil)
+
+	run := func() []string {
+		state, err := LoadState(statePath)
+		if err != nil {
+			t.Fatalf("LoadState() error = %v", err)
+		}
+		checks := CollectFiles(context.Background(), Config{Name: "cert", State: state}, []string{path})
+		if err := state.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		var results []string
+		for _, check := range checks {
+			results = append(results, check.Message)
+			if check.Message == "" && check.AlertLevel != 0 {
+				t.Errorf("expected alert level 0, got %d", check.AlertLevel)
+			}
+			if check.Message != "" && check.AlertLevel != 1 {
+				t.Errorf("expected alert level 1 for change, got %d", check.AlertLevel)
+			}
+		}
+		return results
+	}
+
+	writePEM(t, path, first)
+	if got := run(); got[0] != "" {
+		t.Errorf("expected no change on first run, got %q", got[0])
+	}
+	if got := run(); got[0] != "" {
+		t.Errorf("expected no change for same certificate, got %q", got[0])
+	}
+
+	writePEM(t, path, second)
+	got := run()
+	if !strings.Contains(got[0], "Certificate changed") || !strings.Contains(got[0], "serial") {
+		t.Errorf("expected change message, got %q", got[0])
+	}
+}
+
+func TestCollect_StatePerPort(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	// Two ports on the same host serving different certificates
+	var urls []string
+	for _, cn := range []string{"a.example.com", "b.example.com"} {
+		cert, key := newTestCert(t, cn, false, time.Now().AddDate(1, 0, 0), nil, nil)
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{cert.Raw}, PrivateKey: key}}}
+		server.StartTLS()
+		defer server.Close()
+		urls = append(urls, server.URL)
+	}
+
+	for run := 1; run <= 2; run++ {
+		state, err := LoadState(statePath)
+		if err != nil {
+			t.Fatalf("LoadState() error = %v", err)
+		}
+		cfg := Config{Name: "ssl", Timeout: 5 * time.Second, TLSConfig: &tls.Config{InsecureSkipVerify: true}, State: state}
+		checks := Collect(context.Background(), cfg, urls)
+		if err := state.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		for _, check := range checks {
+			if check.AlertLevel != 0 {
+				t.Errorf("run %d: expected alert level 0 for %s, got %d (%s)", run, check.Service, check.AlertLevel, check.Message)
+			}
+		}
+	}
+}
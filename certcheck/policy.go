@@ -0,0 +1,170 @@
+package certcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	"github.com/alertbingo/alertbingo/api"
+	"github.com/alertbingo/alertbingo/certcheck/ssl"
+)
+
+// Policy describes the TLS requirements endpoints are audited against
+type Policy struct {
+	MinVersion        uint16   // lowest protocol version an endpoint may accept
+	MinRSAKeySize     int      // minimum RSA key size in bits
+	MinECDSAKeySize   int      // minimum ECDSA key size in bits
+	WeakSignatures    []string // signature algorithms to reject, matched case-insensitively (e.g. SHA1, MD5)
+	ViolationSeverity int      // alert level used for violations (1 = warn, 2 = alert)
+}
+
+// DefaultPolicy returns the baseline policy: TLS 1.2+, 2048-bit RSA, 256-bit ECDSA,
+// no SHA-1 or MD5 signatures, with violations raised as alerts
+func DefaultPolicy() Policy {
+	return Policy{
+		MinVersion:        tls.VersionTLS12,
+		MinRSAKeySize:     2048,
+		MinECDSAKeySize:   256,
+		WeakSignatures:    []string{"SHA1", "MD5"},
+		ViolationSeverity: 2,
+	}
+}
+
+// CollectPolicy audits the given URLs against the policy and returns three check
+// payloads per URL: accepted protocol versions, key sizes and signature algorithms
+func CollectPolicy(ctx context.Context, cfg Config, policy Policy, urls []string) []api.CheckPayload {
+	var checks []api.CheckPayload
+
+	for _, u := range urls {
+		for _, opts := range endpointOptions(cfg, u) {
+			checks = append(checks, checkPolicy(ctx, cfg, policy, u, opts)...)
+		}
+	}
+
+	return checks
+}
+
+func checkPolicy(ctx context.Context, cfg Config, policy Policy, rawURL string, opts ssl.Options) []api.CheckPayload {
+	names := []string{cfg.Name + " protocols", cfg.Name + " key", cfg.Name + " signature"}
+
+	result, err := ssl.Probe(rawURL, opts)
+	if err != nil {
+		var checks []api.CheckPayload
+		for _, name := range names {
+			check := errorPayload(cfg, endpointService(rawURL, opts.Address), err)
+			check.Name = name
+			checks = append(checks, check)
+		}
+		return checks
+	}
+
+	protocolValue, protocolViolations := checkProtocols(policy, result.Accepted)
+	keyValue, keyViolations := checkKeySizes(policy, result.Chain)
+	signatureValue, signatureViolations := checkSignatures(policy, result.Chain)
+
+	service := endpointService(result.Host, result.Address)
+	return []api.CheckPayload{
+		policyPayload(cfg, policy, service, names[0], protocolValue, protocolViolations),
+		policyPayload(cfg, policy, service, names[1], keyValue, keyViolations),
+		policyPayload(cfg, policy, service, names[2], signatureValue, signatureViolations),
+	}
+}
+
+// checkProtocols returns the accepted protocol versions and any below the policy minimum
+func checkProtocols(policy Policy, accepted []uint16) (string, []string) {
+	var names, violations []string
+
+	for _, version := range accepted {
+		names = append(names, tls.VersionName(version))
+		if version < policy.MinVersion {
+			violations = append(violations, fmt.Sprintf("accepts %s (minimum %s)", tls.VersionName(version), tls.VersionName(policy.MinVersion)))
+		}
+	}
+
+	return strings.Join(names, ", "), violations
+}
+
+// checkKeySizes returns the leaf key and any certificate keys in the chain below the policy minimum
+func checkKeySizes(policy Policy, chain []*x509.Certificate) (string, []string) {
+	var value string
+	var violations []string
+
+	for i, cert := range chain {
+		algorithm, size := ssl.KeySize(cert)
+		if i == 0 {
+			value = fmt.Sprintf("%s %d", algorithm, size)
+		}
+
+		var minSize int
+		switch cert.PublicKeyAlgorithm {
+		case x509.RSA:
+			minSize = policy.MinRSAKeySize
+		case x509.ECDSA:
+			minSize = policy.MinECDSAKeySize
+		}
+		if size < minSize {
+			violations = append(violations, fmt.Sprintf("%s uses %s %d key (minimum %d)", certLabel(cert), algorithm, size, minSize))
+		}
+	}
+
+	return value, violations
+}
+
+// checkSignatures returns the leaf signature algorithm and any weak signatures in the chain.
+// Self-signed certificates are skipped as their signatures are not relied upon.
+func checkSignatures(policy Policy, chain []*x509.Certificate) (string, []string) {
+	var value string
+	var violations []string
+
+	for i, cert := range chain {
+		algorithm := cert.SignatureAlgorithm.String()
+		if i == 0 {
+			value = algorithm
+		}
+		if ssl.IsSelfSigned(cert) {
+			continue
+		}
+
+		for _, weak := range policy.WeakSignatures {
+			if strings.Contains(strings.ToUpper(algorithm), strings.ToUpper(weak)) {
+				violations = append(violations, fmt.Sprintf("%s signed with %s", certLabel(cert), algorithm))
+				break
+			}
+		}
+	}
+
+	return value, violations
+}
+
+// policyPayload creates a CheckPayload for a single policy check
+func policyPayload(cfg Config, policy Policy, service, name, value string, violations []string) api.CheckPayload {
+	payload := api.CheckPayload{
+		Dashboard:        cfg.Dashboard,
+		Site:             cfg.Site,
+		Service:          service,
+		Name:             name,
+		AlertLevel:       0, // ok
+		Value:            value,
+		Message:          cfg.Message,
+		InactiveExpire:   cfg.InactiveExpire,
+		InactiveEscalate: cfg.InactiveEscalate,
+		Highlighted:      cfg.Highlighted,
+	}
+
+	if len(violations) > 0 {
+		payload.AlertLevel = policy.ViolationSeverity
+		payload.Message = appendAlertReason(cfg.Message, strings.Join(violations, "; "))
+	}
+
+	return payload
+}
+
+// certLabel identifies a certificate in violation messages
+func certLabel(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return fmt.Sprintf("%q", cert.Subject.CommonName)
+	}
+	return cert.Subject.String()
+}
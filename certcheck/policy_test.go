@@ -0,0 +1,147 @@
+package certcheck
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollectPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		minVersion uint16
+		wantLevel  int
+	}{
+		{"modern endpoint", tls.VersionTLS12, 0},
+		{"legacy endpoint", tls.VersionTLS10, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			server.TLS = &tls.Config{MinVersion: tt.minVersion}
+			server.StartTLS()
+			defer server.Close()
+
+			cfg := Config{Dashboard: "test-dash", Site: "test-site", Name: "tls", Timeout: 5 * time.Second}
+			checks := CollectPolicy(context.Background(), cfg, DefaultPolicy(), []string{server.URL})
+
+			if len(checks) != 3 {
+				t.Fatalf("expected 3 checks, got %d", len(checks))
+			}
+			protocols := checks[0]
+			if protocols.Name != "tls protocols" {
+				t.Errorf("expected name 'tls protocols', got %q", protocols.Name)
+			}
+			if protocols.AlertLevel != tt.wantLevel {
+				t.Errorf("expected alert level %d, got %d (%s)", tt.wantLevel, protocols.AlertLevel, protocols.Message)
+			}
+			if !strings.Contains(protocols.Value, "TLS 1.3") {
+				t.Errorf("expected TLS 1.3 to be accepted, got %q", protocols.Value)
+			}
+			if tt.wantLevel > 0 && !strings.Contains(protocols.Message, "accepts TLS 1.0") {
+				t.Errorf("expected TLS 1.0 violation, got %q", protocols.Message)
+			}
+		})
+	}
+}
+
+func TestCollectPolicy_ConnectionError(t *testing.T) {
+	cfg := Config{Name: "tls", Timeout: time.Second}
+	checks := CollectPolicy(context.Background(), cfg, DefaultPolicy(), []string{"https://localhost:1"})
+
+	if len(checks) != 3 {
+		t.Fatalf("expected 3 checks, got %d", len(checks))
+	}
+	for _, check := range checks {
+		if check.AlertLevel != 2 || check.Value != "Error" {
+			t.Errorf("expected error check, got %+v", check)
+		}
+	}
+}
+
+func TestCheckKeySizes(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "weak.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	weak, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	strong, _ := newTestCert(t, "strong.example.com", false, time.Now().AddDate(1, 0, 0), nil, nil)
+
+	value, violations := checkKeySizes(DefaultPolicy(), []*x509.Certificate{weak})
+	if value != "RSA 1024" {
+		t.Errorf("expected value 'RSA 1024', got %q", value)
+	}
+	if len(violations) != 1 {
+		t.Errorf("expected 1 violation, got %v", violations)
+	}
+
+	value, violations = checkKeySizes(DefaultPolicy(), []*x509.Certificate{strong})
+	if value != "ECDSA 256" || len(violations) != 0 {
+		t.Errorf("expected ECDSA 256 without violations, got %q %v", value, violations)
+	}
+}
+
+func TestCheckSignatures(t *testing.T) {
+	ca, caKey := newTestCert(t, "Test CA", true, time.Now().AddDate(5, 0, 0), nil, nil)
+	leaf, _ := newTestCert(t, "www.example.com", false, time.Now().AddDate(1, 0, 0), ca, caKey)
+
+	policy := DefaultPolicy()
+	value, violations := checkSignatures(policy, []*x509.Certificate{leaf, ca})
+	if value != "ECDSA-SHA256" || len(violations) != 0 {
+		t.Errorf("expected ECDSA-SHA256 without violations, got %q %v", value, violations)
+	}
+
+	// The self-signed root is never flagged
+	policy.WeakSignatures = []string{"sha256"}
+	_, violations = checkSignatures(policy, []*x509.Certificate{leaf, ca})
+	if len(violations) != 1 || !strings.Contains(violations[0], "www.example.com") {
+		t.Errorf("expected only the leaf to violate, got %v", violations)
+	}
+}
+
+func TestCollectPolicy_LegacyCipherSuites(t *testing.T) {
+	// An endpoint that only offers TLS 1.0 with an RSA key exchange suite, which
+	// Go no longer enables by default
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{
+		MinVersion:   tls.VersionTLS10,
+		MaxVersion:   tls.VersionTLS10,
+		CipherSuites: []uint16{tls.TLS_RSA_WITH_AES_128_CBC_SHA},
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	cfg := Config{Dashboard: "test-dash", Site: "test-site", Name: "tls", Timeout: 5 * time.Second}
+	checks := CollectPolicy(context.Background(), cfg, DefaultPolicy(), []string{server.URL})
+
+	protocols := checks[0]
+	if protocols.Value != "TLS 1.0" {
+		t.Errorf("expected only TLS 1.0 to be accepted, got %q (%s)", protocols.Value, protocols.Message)
+	}
+	if protocols.AlertLevel != 2 || !strings.Contains(protocols.Message, "accepts TLS 1.0") {
+		t.Errorf("expected TLS 1.0 violation, got level %d (%s)", protocols.AlertLevel, protocols.Message)
+	}
+}
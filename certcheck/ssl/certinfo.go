@@ -0,0 +1,112 @@
+package ssl
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// newCertInfo builds a CertInfo from the metadata of a parsed certificate
+func newCertInfo(cert *x509.Certificate) *CertInfo {
+	sans := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, cert.EmailAddresses...)
+	for _, u := range cert.URIs {
+		sans = append(sans, u.String())
+	}
+
+	fingerprint := sha256.Sum256(cert.Raw)
+	spkiHash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+	return &CertInfo{
+		CommonName:         cert.Subject.CommonName,
+		Subject:            cert.Subject.String(),
+		Issuer:             cert.Issuer.String(),
+		SANs:               sans,
+		SerialNumber:       formatHex(cert.SerialNumber.Bytes()),
+		FingerprintSHA256:  formatHex(fingerprint[:]),
+		SPKIPin:            "sha256/" + base64.StdEncoding.EncodeToString(spkiHash[:]),
+		KeyAlgorithm:       cert.PublicKeyAlgorithm.String(),
+		KeySize:            publicKeySize(cert.PublicKey),
+		SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+		MustStaple:         hasMustStaple(cert),
+		ExpiresAt:          cert.NotAfter,
+		DaysUntil:          int(time.Until(cert.NotAfter).Hours() / 24),
+		cert:               cert,
+	}
+}
+
+// setConnectionState records the negotiated TLS parameters, stapled OCSP response
+// and issuing certificate on the CertInfo
+func (c *CertInfo) setConnectionState(state tls.ConnectionState) {
+	c.TLSVersion = tls.VersionName(state.Version)
+	c.CipherSuite = tls.CipherSuiteName(state.CipherSuite)
+	c.OCSPStaple = state.OCSPResponse
+
+	// Prefer the verified chain; fall back to the certificates sent by the server
+	if len(state.VerifiedChains) > 0 && len(state.VerifiedChains[0]) > 1 {
+		c.issuer = state.VerifiedChains[0][1]
+	} else if len(state.PeerCertificates) > 1 {
+		c.issuer = state.PeerCertificates[1]
+	}
+}
+
+// Summary returns a one-line, human readable summary of the certificate metadata
+func (c *CertInfo) Summary() string {
+	parts := []string{
+		fmt.Sprintf("Subject: %s", c.Subject),
+		fmt.Sprintf("Issuer: %s", c.Issuer),
+	}
+	if len(c.SANs) > 0 {
+		parts = append(parts, fmt.Sprintf("SANs: %s", strings.Join(c.SANs, ", ")))
+	}
+	parts = append(parts,
+		fmt.Sprintf("Serial: %s", c.SerialNumber),
+		fmt.Sprintf("SHA-256: %s", c.FingerprintSHA256),
+	)
+	if c.KeySize > 0 {
+		parts = append(parts, fmt.Sprintf("Key: %s %d", c.KeyAlgorithm, c.KeySize))
+	} else {
+		parts = append(parts, fmt.Sprintf("Key: %s", c.KeyAlgorithm))
+	}
+	parts = append(parts,
+		fmt.Sprintf("Signature: %s", c.SignatureAlgorithm),
+		fmt.Sprintf("Expires: %s", c.ExpiresAt.UTC().Format(time.RFC3339)),
+	)
+	if c.TLSVersion != "" {
+		parts = append(parts, fmt.Sprintf("TLS: %s %s", c.TLSVersion, c.CipherSuite))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// publicKeySize returns the size in bits of a public key, or 0 if unknown
+func publicKeySize(pub any) int {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return key.N.BitLen()
+	case *ecdsa.PublicKey:
+		return key.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return 256
+	default:
+		return 0
+	}
+}
+
+// formatHex formats bytes as colon separated upper-case hex (e.g. 0A:1B:2C)
+func formatHex(b []byte) string {
+	hexParts := make([]string, len(b))
+	for i, v := range b {
+		hexParts[i] = fmt.Sprintf("%02X", v)
+	}
+	return strings.Join(hexParts, ":")
+}
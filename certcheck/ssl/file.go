@@ -0,0 +1,142 @@
+package ssl
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// CheckFile reads a certificate file from disk and returns information about each
+// leaf certificate it contains. PEM bundles, DER encoded certificates and PKCS#12
+// archives are supported; password is only used for PKCS#12 archives.
+func CheckFile(path, password string) ([]*CertInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	certs, err := parseCertificates(data, path, password)
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found")
+	}
+
+	var infos []*CertInfo
+	for _, cert := range leafCertificates(certs) {
+		certInfo := newCertInfo(cert)
+		certInfo.Path = path
+		infos = append(infos, certInfo)
+	}
+
+	return infos, nil
+}
+
+// ExpandPaths expands glob patterns and file:// URLs into a list of file paths
+func ExpandPaths(patterns []string) ([]string, error) {
+	var paths []string
+
+	for _, pattern := range patterns {
+		pattern = strings.TrimPrefix(pattern, "file://")
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			// Keep the literal path so the missing file is reported as a check
+			matches = []string{pattern}
+		}
+		paths = append(paths, matches...)
+	}
+
+	return paths, nil
+}
+
+// IsFileTarget reports whether a certcheck target refers to a file on disk
+func IsFileTarget(target string) bool {
+	return strings.HasPrefix(target, "file://")
+}
+
+// parseCertificates decodes all certificates from PEM, DER or PKCS#12 data
+func parseCertificates(data []byte, path, password string) ([]*x509.Certificate, error) {
+	if bytes.Contains(data, []byte("-----BEGIN")) {
+		return parsePEM(data)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".p12" || ext == ".pfx" {
+		return parsePKCS12(data, password)
+	}
+
+	// Try DER first, then fall back to PKCS#12 for archives without a known extension
+	certs, derErr := x509.ParseCertificates(data)
+	if derErr == nil {
+		return certs, nil
+	}
+	certs, err := parsePKCS12(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", derErr)
+	}
+	return certs, nil
+}
+
+// parsePEM decodes all CERTIFICATE blocks from PEM data, skipping keys and other blocks
+func parsePEM(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PEM certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+
+	return certs, nil
+}
+
+// parsePKCS12 decodes the leaf and CA certificates from a PKCS#12 archive
+func parsePKCS12(data []byte, password string) ([]*x509.Certificate, error) {
+	_, leaf, caCerts, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		// Trust stores contain certificates without a private key
+		trustCerts, trustErr := pkcs12.DecodeTrustStore(data, password)
+		if trustErr != nil {
+			return nil, fmt.Errorf("failed to parse PKCS#12 archive: %w", err)
+		}
+		return trustCerts, nil
+	}
+	return append([]*x509.Certificate{leaf}, caCerts...), nil
+}
+
+// leafCertificates returns the non-CA certificates, or all certificates if the
+// file only contains CAs (e.g. a CA bundle)
+func leafCertificates(certs []*x509.Certificate) []*x509.Certificate {
+	var leaves []*x509.Certificate
+	for _, cert := range certs {
+		if !cert.IsCA {
+			leaves = append(leaves, cert)
+		}
+	}
+	if len(leaves) == 0 {
+		return certs
+	}
+	return leaves
+}
@@ -0,0 +1,127 @@
+package ssl
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// oidTLSFeature is the TLS feature extension used to mark certificates as must-staple (RFC 7633)
+var oidTLSFeature = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 24}
+
+// OCSPStatus contains the revocation status of a certificate from an OCSP response
+type OCSPStatus struct {
+	Status     string // good, revoked or unknown
+	RevokedAt  time.Time
+	ThisUpdate time.Time
+	NextUpdate time.Time
+}
+
+// IsExpired reports whether the response is past its next update time
+func (s *OCSPStatus) IsExpired() bool {
+	return !s.NextUpdate.IsZero() && time.Now().After(s.NextUpdate)
+}
+
+// StapledStatus parses the OCSP response stapled to the handshake. It returns nil
+// if no response was stapled.
+func StapledStatus(certInfo *CertInfo) (*OCSPStatus, error) {
+	if len(certInfo.OCSPStaple) == 0 {
+		return nil, nil
+	}
+	if certInfo.issuer == nil {
+		return nil, fmt.Errorf("issuer certificate unknown, cannot verify stapled OCSP response")
+	}
+
+	resp, err := ocsp.ParseResponseForCert(certInfo.OCSPStaple, certInfo.cert, certInfo.issuer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stapled OCSP response: %w", err)
+	}
+
+	return newOCSPStatus(resp), nil
+}
+
+// QueryOCSP asks an OCSP responder for the certificate's revocation status using
+// client. The responder from the certificate's AIA extension is used unless
+// responderURL is set.
+func QueryOCSP(ctx context.Context, client *http.Client, certInfo *CertInfo, responderURL string) (*OCSPStatus, error) {
+	if certInfo.cert == nil || certInfo.issuer == nil {
+		return nil, fmt.Errorf("issuer certificate unknown, cannot query OCSP responder")
+	}
+
+	if responderURL == "" {
+		if len(certInfo.cert.OCSPServer) == 0 {
+			return nil, fmt.Errorf("certificate has no OCSP responder")
+		}
+		responderURL = certInfo.cert.OCSPServer[0]
+	}
+
+	req, err := ocsp.CreateRequest(certInfo.cert, certInfo.issuer, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OCSP request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, responderURL, bytes.NewReader(req))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OCSP request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/ocsp-request")
+
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("OCSP request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OCSP responder returned status %d", httpResp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(httpResp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OCSP response: %w", err)
+	}
+
+	resp, err := ocsp.ParseResponseForCert(body, certInfo.cert, certInfo.issuer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OCSP response: %w", err)
+	}
+
+	return newOCSPStatus(resp), nil
+}
+
+// newOCSPStatus converts a parsed OCSP response to an OCSPStatus
+func newOCSPStatus(resp *ocsp.Response) *OCSPStatus {
+	status := &OCSPStatus{
+		ThisUpdate: resp.ThisUpdate,
+		NextUpdate: resp.NextUpdate,
+	}
+
+	switch resp.Status {
+	case ocsp.Good:
+		status.Status = "good"
+	case ocsp.Revoked:
+		status.Status = "revoked"
+		status.RevokedAt = resp.RevokedAt
+	default:
+		status.Status = "unknown"
+	}
+
+	return status
+}
+
+// hasMustStaple reports whether the certificate requires a stapled OCSP response
+func hasMustStaple(cert *x509.Certificate) bool {
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidTLSFeature) {
+			return true
+		}
+	}
+	return false
+}
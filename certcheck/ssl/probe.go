@@ -0,0 +1,114 @@
+package ssl
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"strings"
+)
+
+// ProtocolVersions lists the TLS protocol versions tried by Probe, oldest first
+var ProtocolVersions = []uint16{
+	tls.VersionTLS10,
+	tls.VersionTLS11,
+	tls.VersionTLS12,
+	tls.VersionTLS13,
+}
+
+// ProbeResult contains the protocol versions and certificate chain offered by an endpoint
+type ProbeResult struct {
+	Host     string
+	Address  string
+	Accepted []uint16 // protocol versions the endpoint completed a handshake with
+	Chain    []*x509.Certificate
+}
+
+// Probe connects to the given URL once per protocol version in ProtocolVersions,
+// constraining each attempt to a single version, and records which versions the
+// endpoint accepts along with the certificate chain it presents. Certificates are
+// not verified, so that weak chains can still be inspected. TLS 1.0 and 1.1 are
+// probed with every cipher suite Go implements, including the insecure ones,
+// since legacy endpoints often support nothing else.
+func Probe(rawURL string, opts Options) (*ProbeResult, error) {
+	host, port, err := ParseTarget(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	serverName := opts.serverName(host)
+
+	result := &ProbeResult{Host: serverName, Address: opts.Address}
+	var lastErr error
+
+	for _, version := range ProtocolVersions {
+		tlsConfig := opts.tlsConfig()
+		tlsConfig.ServerName = serverName
+		tlsConfig.MinVersion = version
+		tlsConfig.MaxVersion = version
+		tlsConfig.InsecureSkipVerify = true
+		if version < tls.VersionTLS12 {
+			tlsConfig.CipherSuites = legacyCipherSuites()
+		}
+
+		conn, err := dial(opts.address(host), port, opts.Timeout, tlsConfig)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		state := conn.ConnectionState()
+		conn.Close()
+
+		result.Accepted = append(result.Accepted, version)
+		if len(state.PeerCertificates) > 0 {
+			result.Chain = state.PeerCertificates
+		}
+	}
+
+	if len(result.Accepted) == 0 {
+		return nil, lastErr
+	}
+
+	return result, nil
+}
+
+// legacyCipherSuites returns the IDs of every TLS 1.0-1.2 cipher suite Go
+// implements, secure ones first
+func legacyCipherSuites() []uint16 {
+	var ids []uint16
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		ids = append(ids, suite.ID)
+	}
+	return ids
+}
+
+// ParseVersion converts a TLS version string such as "1.2" or "TLS1.2" to its protocol constant
+func ParseVersion(version string) (uint16, error) {
+	v := strings.TrimSpace(strings.ToUpper(version))
+	v = strings.TrimPrefix(strings.TrimPrefix(v, "TLS"), " ")
+	v = strings.TrimPrefix(v, "V")
+
+	switch v {
+	case "1.0", "1":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("invalid TLS version: %s (must be 1.0, 1.1, 1.2 or 1.3)", version)
+	}
+}
+
+// KeySize returns the algorithm and size in bits of a certificate's public key
+func KeySize(cert *x509.Certificate) (string, int) {
+	return cert.PublicKeyAlgorithm.String(), publicKeySize(cert.PublicKey)
+}
+
+// IsSelfSigned reports whether a certificate's issuer is the same as its subject,
+// i.e. its signature does not need to be trusted
+func IsSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawSubject, cert.RawIssuer)
+}
@@ -3,6 +3,7 @@ package ssl
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net"
 	"net/url"
@@ -11,17 +12,77 @@ import (
 
 // CertInfo contains information about an SSL certificate
 type CertInfo struct {
-	Host      string
-	ExpiresAt time.Time
-	DaysUntil int
+	Host               string
+	Address            string // set when connecting to an address other than Host
+	Path               string // set for certificates read from disk
+	CommonName         string
+	Subject            string
+	Issuer             string
+	SANs               []string
+	SerialNumber       string
+	FingerprintSHA256  string
+	SPKIPin            string // base64 SHA-256 of the public key info, as sha256/<base64>
+	KeyAlgorithm       string
+	KeySize            int // in bits, 0 if unknown
+	SignatureAlgorithm string
+	TLSVersion         string // empty for certificates read from disk
+	CipherSuite        string
+	OCSPStaple         []byte // OCSP response stapled to the handshake, if any
+	MustStaple         bool   // certificate has the TLS feature (must-staple) extension
+	ExpiresAt          time.Time
+	DaysUntil          int
+
+	cert   *x509.Certificate
+	issuer *x509.Certificate // nil if the issuer is unknown
+}
+
+// Options configures how an endpoint is connected to
+type Options struct {
+	Timeout    time.Duration
+	ServerName string      // SNI and verification hostname; defaults to the URL host
+	Address    string      // IP or host to connect to instead of the URL host
+	TLSConfig  *tls.Config // base TLS configuration (CAs, client certificates); may be nil
 }
 
 // CheckCertificate connects to the given URL and returns certificate information
-func CheckCertificate(rawURL string, timeout time.Duration) (*CertInfo, error) {
-	// Parse the URL
+func CheckCertificate(rawURL string, opts Options) (*CertInfo, error) {
+	host, port, err := ParseTarget(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	serverName := opts.serverName(host)
+
+	// Connect with TLS
+	tlsConfig := opts.tlsConfig()
+	tlsConfig.ServerName = serverName
+
+	conn, err := dial(opts.address(host), port, opts.Timeout, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// Get the certificate chain
+	state := conn.ConnectionState()
+	certs := state.PeerCertificates
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found")
+	}
+
+	// Use the leaf certificate (first in chain)
+	certInfo := newCertInfo(certs[0])
+	certInfo.Host = serverName
+	certInfo.Address = opts.Address
+	certInfo.setConnectionState(state)
+
+	return certInfo, nil
+}
+
+// ParseTarget returns the host and port to connect to for the given URL
+func ParseTarget(rawURL string) (string, string, error) {
 	parsedURL, err := url.Parse(rawURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse URL: %w", err)
+		return "", "", fmt.Errorf("failed to parse URL: %w", err)
 	}
 
 	host := parsedURL.Hostname()
@@ -30,33 +91,44 @@ func CheckCertificate(rawURL string, timeout time.Duration) (*CertInfo, error) {
 		port = "443"
 	}
 
-	// Connect with TLS
+	return host, port, nil
+}
+
+// serverName returns the SNI hostname to use for the given URL host
+func (o Options) serverName(host string) string {
+	if o.ServerName != "" {
+		return o.ServerName
+	}
+	return host
+}
+
+// tlsConfig returns a copy of the base TLS configuration, or an empty configuration
+// that verifies against the system roots
+func (o Options) tlsConfig() *tls.Config {
+	if o.TLSConfig == nil {
+		return &tls.Config{}
+	}
+	return o.TLSConfig.Clone()
+}
+
+// address returns the host or IP to connect to for the given URL host
+func (o Options) address(host string) string {
+	if o.Address != "" {
+		return o.Address
+	}
+	return host
+}
+
+// dial opens a TLS connection to host:port using the given TLS configuration
+func dial(host, port string, timeout time.Duration, tlsConfig *tls.Config) (*tls.Conn, error) {
 	dialer := &net.Dialer{
 		Timeout: timeout,
 	}
 
-	conn, err := tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(host, port), &tls.Config{
-		InsecureSkipVerify: false,
-	})
+	conn, err := tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(host, port), tlsConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect: %w", err)
 	}
-	defer conn.Close()
-
-	// Get the certificate chain
-	certs := conn.ConnectionState().PeerCertificates
-	if len(certs) == 0 {
-		return nil, fmt.Errorf("no certificates found")
-	}
 
-	// Use the leaf certificate (first in chain)
-	cert := certs[0]
-	expiresAt := cert.NotAfter
-	daysUntil := int(time.Until(expiresAt).Hours() / 24)
-
-	return &CertInfo{
-		Host:      host,
-		ExpiresAt: expiresAt,
-		DaysUntil: daysUntil,
-	}, nil
+	return conn, nil
 }
@@ -0,0 +1,542 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alertbingo/alertbingo/api"
+	"github.com/alertbingo/alertbingo/certcheck"
+	"github.com/alertbingo/alertbingo/certcheck/ssl"
+	"github.com/alertbingo/alertbingo/execcheck"
+	"github.com/alertbingo/alertbingo/hoststats"
+	"github.com/alertbingo/alertbingo/nagios"
+	"github.com/alertbingo/alertbingo/plugincheck"
+	"github.com/alertbingo/alertbingo/proxyconfig"
+	"github.com/alertbingo/alertbingo/tlsconfig"
+	"github.com/alertbingo/alertbingo/urlcheck"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultTimeout is the connection timeout for URL and certificate checks, as on the command line
+const defaultTimeout = 10 * time.Second
+
+// defaultConcurrency is how many URLs or plugins are checked at once, as on the command line
+const defaultConcurrency = 10
+
+func newHostStats(common Common, config *yaml.Node) (Checker, error) {
+	// hoststats has no settings of its own
+	if err := DecodeConfig(config, &struct{}{}); err != nil {
+		return nil, err
+	}
+	if common.Service == "" {
+		return nil, fmt.Errorf("service is required")
+	}
+
+	cfg := hoststats.Config{
+		Dashboard:        common.Dashboard,
+		Site:             common.Site,
+		Service:          common.Service,
+		Message:          common.Message,
+		InactiveExpire:   common.InactiveExpire,
+		InactiveEscalate: common.InactiveEscalate,
+		Highlighted:      common.Highlighted,
+	}
+
+	return Func(func(ctx context.Context) ([]api.CheckPayload, error) {
+		return hoststats.Collect(ctx, cfg)
+	}), nil
+}
+
+// URLCheckConfig is the configuration of a urlcheck check
+type URLCheckConfig struct {
+	Targets       []urlcheck.CheckParams `yaml:"targets"`
+	Scenarios     []string               `yaml:"scenarios"` // scenario file paths
+	Timeout       time.Duration          `yaml:"timeout"`
+	Concurrency   int                    `yaml:"concurrency"`
+	Attempts      int                    `yaml:"attempts"`
+	AttemptDelay  time.Duration          `yaml:"attempt_delay"`
+	SlowThreshold time.Duration          `yaml:"slow_threshold"`
+	SlowDNS       time.Duration          `yaml:"slow_dns"`
+	SlowConnect   time.Duration          `yaml:"slow_connect"`
+	SlowTLS       time.Duration          `yaml:"slow_tls"`
+	SlowTTFB      time.Duration          `yaml:"slow_ttfb"`
+	BodyLimit     int64                  `yaml:"body_limit"`
+	BaselineFile  string                 `yaml:"baseline_file"` // saved after each run
+	IPv4          bool                   `yaml:"ipv4"`
+	IPv6          bool                   `yaml:"ipv6"`
+	Resolve       []string               `yaml:"resolve"` // host:port:address
+	EachAddress   bool                   `yaml:"each_address"`
+	Proxy         proxyconfig.Options    `yaml:"proxy"`
+	TLS           tlsconfig.Options      `yaml:",inline"`
+}
+
+func newURLCheck(common Common, config *yaml.Node) (Checker, error) {
+	var c URLCheckConfig
+	if err := DecodeConfig(config, &c); err != nil {
+		return nil, err
+	}
+	return NewURLCheck(common, c)
+}
+
+// NewURLCheck creates a urlcheck Checker
+func NewURLCheck(common Common, c URLCheckConfig) (Checker, error) {
+	if common.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if len(c.Targets) == 0 && len(c.Scenarios) == 0 {
+		return nil, fmt.Errorf("targets or scenarios are required")
+	}
+	for i, target := range c.Targets {
+		if target.URL == "" {
+			return nil, fmt.Errorf("target %d has no url", i+1)
+		}
+		if err := urlcheck.ValidateAssertions(&c.Targets[i]); err != nil {
+			return nil, fmt.Errorf("%s: %w", target.URL, err)
+		}
+	}
+
+	var scenarios []*urlcheck.Scenario
+	for _, path := range c.Scenarios {
+		scenario, err := urlcheck.LoadScenario(path)
+		if err != nil {
+			return nil, err
+		}
+		scenarios = append(scenarios, scenario)
+	}
+
+	cfg := urlcheck.Config{
+		Dashboard:        common.Dashboard,
+		Site:             common.Site,
+		Name:             common.Name,
+		Message:          common.Message,
+		InactiveExpire:   common.InactiveExpire,
+		InactiveEscalate: common.InactiveEscalate,
+		Highlighted:      common.Highlighted,
+		Timeout:          orDefault(c.Timeout, defaultTimeout),
+		SlowThresholds: urlcheck.SlowThresholds{
+			Total:   c.SlowThreshold,
+			DNS:     c.SlowDNS,
+			Connect: c.SlowConnect,
+			TLS:     c.SlowTLS,
+			TTFB:    c.SlowTTFB,
+		},
+		BodyLimit:    c.BodyLimit,
+		EachAddress:  c.EachAddress,
+		Attempts:     c.Attempts,
+		AttemptDelay: orDefault(c.AttemptDelay, time.Second),
+	}
+
+	switch {
+	case c.IPv4 && c.IPv6:
+		return nil, fmt.Errorf("ipv4 and ipv6 cannot be used together")
+	case c.IPv4:
+		cfg.Network = "tcp4"
+	case c.IPv6:
+		cfg.Network = "tcp6"
+	}
+	for _, spec := range c.Resolve {
+		resolve, err := urlcheck.ParseResolve(spec)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Resolve = append(cfg.Resolve, resolve)
+	}
+
+	var err error
+	if cfg.Proxy, err = c.Proxy.Func(); err != nil {
+		return nil, err
+	}
+	if cfg.TLSConfig, err = c.TLS.Build(); err != nil {
+		return nil, err
+	}
+	if c.BaselineFile != "" {
+		if cfg.Baseline, err = urlcheck.LoadBaseline(c.BaselineFile); err != nil {
+			return nil, err
+		}
+	}
+	concurrency := orDefault(c.Concurrency, defaultConcurrency)
+
+	return Func(func(ctx context.Context) ([]api.CheckPayload, error) {
+		checks := urlcheck.CheckAll(ctx, cfg, c.Targets, concurrency)
+		for _, scenario := range scenarios {
+			checks = append(checks, urlcheck.RunScenario(ctx, cfg, scenario)...)
+		}
+		if cfg.Baseline != nil {
+			if err := cfg.Baseline.Save(); err != nil {
+				return nil, err
+			}
+		}
+		return checks, nil
+	}), nil
+}
+
+// CertCheckConfig is the configuration of a certcheck check
+type CertCheckConfig struct {
+	URLs              []string          `yaml:"urls"`
+	Files             []string          `yaml:"files"` // certificate files or globs
+	Timeout           time.Duration     `yaml:"timeout"`
+	Details           bool              `yaml:"details"`
+	PKCS12Password    string            `yaml:"pkcs12_password"`
+	SNI               string            `yaml:"sni"`
+	ConnectTo         []string          `yaml:"connect_to"` // host:port:address
+	Pins              []string          `yaml:"pins"`       // [target=]sha256/<base64> or hex fingerprints
+	StateFile         string            `yaml:"state_file"` // saved after each run
+	OCSP              bool              `yaml:"ocsp"`
+	OCSPResponder     string            `yaml:"ocsp_responder"`
+	RequireOCSPStaple bool              `yaml:"require_ocsp_staple"`
+	Policy            *PolicyConfig     `yaml:"policy"` // nil skips the policy audit
+	TLS               tlsconfig.Options `yaml:",inline"`
+}
+
+// PolicyConfig is the TLS policy endpoints are audited against. Unset settings
+// take their values from certcheck.DefaultPolicy.
+type PolicyConfig struct {
+	MinTLS         string   `yaml:"min_tls"` // e.g. 1.2
+	MinRSABits     int      `yaml:"min_rsa_bits"`
+	MinECDSABits   int      `yaml:"min_ecdsa_bits"`
+	WeakSignatures []string `yaml:"weak_signatures"`
+	Level          string   `yaml:"level"` // warn or alert
+}
+
+// Policy returns the certcheck policy for the configuration
+func (p PolicyConfig) Policy() (certcheck.Policy, error) {
+	policy := certcheck.DefaultPolicy()
+
+	if p.MinTLS != "" {
+		minVersion, err := ssl.ParseVersion(p.MinTLS)
+		if err != nil {
+			return policy, err
+		}
+		policy.MinVersion = minVersion
+	}
+	if p.Level != "" {
+		severity, err := api.ParseAlertLevel(p.Level)
+		if err != nil {
+			return policy, err
+		}
+		policy.ViolationSeverity = severity
+	}
+	policy.MinRSAKeySize = orDefault(p.MinRSABits, policy.MinRSAKeySize)
+	policy.MinECDSAKeySize = orDefault(p.MinECDSABits, policy.MinECDSAKeySize)
+	if p.WeakSignatures != nil {
+		policy.WeakSignatures = p.WeakSignatures
+	}
+
+	return policy, nil
+}
+
+func newCertCheck(common Common, config *yaml.Node) (Checker, error) {
+	var c CertCheckConfig
+	if err := DecodeConfig(config, &c); err != nil {
+		return nil, err
+	}
+	return NewCertCheck(common, c)
+}
+
+// NewCertCheck creates a certcheck Checker
+func NewCertCheck(common Common, c CertCheckConfig) (Checker, error) {
+	if common.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if len(c.URLs) == 0 && len(c.Files) == 0 {
+		return nil, fmt.Errorf("urls or files are required")
+	}
+
+	cfg := certcheck.Config{
+		Dashboard:        common.Dashboard,
+		Site:             common.Site,
+		Name:             common.Name,
+		Message:          common.Message,
+		InactiveExpire:   common.InactiveExpire,
+		InactiveEscalate: common.InactiveEscalate,
+		Highlighted:      common.Highlighted,
+		Timeout:          orDefault(c.Timeout, defaultTimeout),
+		PKCS12Password:   c.PKCS12Password,
+		Details:          c.Details,
+		SNI:              c.SNI,
+		OCSP: certcheck.OCSPOptions{
+			Query:         c.OCSP || c.OCSPResponder != "",
+			ResponderURL:  c.OCSPResponder,
+			RequireStaple: c.RequireOCSPStaple,
+		},
+	}
+
+	for _, spec := range c.ConnectTo {
+		connectTo, err := certcheck.ParseConnectTo(spec)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ConnectTo = append(cfg.ConnectTo, connectTo)
+	}
+
+	var err error
+	if cfg.Pins, err = certcheck.ParsePins(c.Pins); err != nil {
+		return nil, err
+	}
+	if cfg.TLSConfig, err = c.TLS.Build(); err != nil {
+		return nil, err
+	}
+	if cfg.OCSP.Query {
+		cfg.OCSP.Client = certcheck.NewOCSPClient(cfg)
+	}
+	if c.StateFile != "" {
+		if cfg.State, err = certcheck.LoadState(c.StateFile); err != nil {
+			return nil, err
+		}
+	}
+
+	var policy certcheck.Policy
+	var endpoints []string
+	if c.Policy != nil {
+		if policy, err = c.Policy.Policy(); err != nil {
+			return nil, err
+		}
+		for _, u := range c.URLs {
+			if !ssl.IsFileTarget(u) {
+				endpoints = append(endpoints, u)
+			}
+		}
+	}
+
+	return Func(func(ctx context.Context) ([]api.CheckPayload, error) {
+		checks := certcheck.Collect(ctx, cfg, c.URLs)
+		if len(c.Files) > 0 {
+			checks = append(checks, certcheck.CollectFiles(ctx, cfg, c.Files)...)
+		}
+		if len(endpoints) > 0 {
+			checks = append(checks, certcheck.CollectPolicy(ctx, cfg, policy, endpoints)...)
+		}
+		if cfg.State != nil {
+			if err := cfg.State.Save(); err != nil {
+				return nil, err
+			}
+		}
+		return checks, nil
+	}), nil
+}
+
+// ExecConfig is the configuration of an exec check
+type ExecConfig struct {
+	Command     []string      `yaml:"command"`
+	Timeout     time.Duration `yaml:"timeout"`
+	ExitLevels  []string      `yaml:"exit_levels"`  // e.g. ["1=warn"]
+	StderrLines *int          `yaml:"stderr_lines"` // nil for execcheck.DefaultTailLines
+}
+
+func newExec(common Common, config *yaml.Node) (Checker, error) {
+	var c ExecConfig
+	if err := DecodeConfig(config, &c); err != nil {
+		return nil, err
+	}
+	return NewExec(common, c)
+}
+
+// Exec is an exec check
+type Exec struct {
+	cfg     execcheck.Config
+	command []string
+}
+
+// NewExec creates an exec check
+func NewExec(common Common, c ExecConfig) (*Exec, error) {
+	if common.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if len(c.Command) == 0 {
+		return nil, fmt.Errorf("command is required")
+	}
+
+	cfg := execcheck.Config{
+		Dashboard:        common.Dashboard,
+		Site:             common.Site,
+		Service:          common.Service,
+		Name:             common.Name,
+		Message:          common.Message,
+		InactiveExpire:   common.InactiveExpire,
+		InactiveEscalate: common.InactiveEscalate,
+		Highlighted:      common.Highlighted,
+		Timeout:          c.Timeout,
+		TailLines:        c.StderrLines,
+	}
+	if cfg.Service == "" {
+		var err error
+		if cfg.Service, err = hostname(); err != nil {
+			return nil, err
+		}
+	}
+	for _, spec := range c.ExitLevels {
+		levels, err := execcheck.ParseExitLevel(spec)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ExitLevels = append(cfg.ExitLevels, levels...)
+	}
+
+	return &Exec{cfg: cfg, command: c.Command}, nil
+}
+
+// Run runs the command and returns its check
+func (e *Exec) Run(ctx context.Context) ([]api.CheckPayload, error) {
+	_, check := e.RunCommand(ctx)
+	return []api.CheckPayload{check}, nil
+}
+
+// RunCommand runs the command and returns its result along with its check, for
+// callers that exit as the command did
+func (e *Exec) RunCommand(ctx context.Context) (execcheck.Result, api.CheckPayload) {
+	return execcheck.Run(ctx, e.cfg, e.command)
+}
+
+// NagiosConfig is the configuration of a nagios check: a single plugin command,
+// a list of plugins, a plugins file, or any of these together
+type NagiosConfig struct {
+	Command     []string        `yaml:"command"`
+	ValueLabel  string          `yaml:"value_label"`
+	Plugins     []nagios.Plugin `yaml:"plugins"`
+	PluginsFile string          `yaml:"plugins_file"`
+	Timeout     time.Duration   `yaml:"timeout"`
+	Concurrency int             `yaml:"concurrency"`
+}
+
+func newNagios(common Common, config *yaml.Node) (Checker, error) {
+	var c NagiosConfig
+	if err := DecodeConfig(config, &c); err != nil {
+		return nil, err
+	}
+	return NewNagios(common, c)
+}
+
+// NewNagios creates a nagios Checker
+func NewNagios(common Common, c NagiosConfig) (Checker, error) {
+	plugins := c.Plugins
+	if len(c.Command) > 0 {
+		plugins = append([]nagios.Plugin{{Name: common.Name, Command: c.Command, ValueLabel: c.ValueLabel}}, plugins...)
+	}
+	for i, plugin := range plugins {
+		if len(plugin.Command) == 0 {
+			return nil, fmt.Errorf("plugin %d has no command", i+1)
+		}
+	}
+	if c.PluginsFile != "" {
+		filePlugins, err := nagios.LoadPlugins(c.PluginsFile)
+		if err != nil {
+			return nil, err
+		}
+		plugins = append(plugins, filePlugins...)
+	}
+	if len(plugins) == 0 {
+		return nil, fmt.Errorf("command, plugins or plugins_file are required")
+	}
+
+	cfg := nagios.Config{
+		Dashboard:        common.Dashboard,
+		Site:             common.Site,
+		Service:          common.Service,
+		Message:          common.Message,
+		InactiveExpire:   common.InactiveExpire,
+		InactiveEscalate: common.InactiveEscalate,
+		Highlighted:      common.Highlighted,
+		Timeout:          c.Timeout,
+	}
+	if cfg.Service == "" {
+		var err error
+		if cfg.Service, err = hostname(); err != nil {
+			return nil, err
+		}
+	}
+	concurrency := orDefault(c.Concurrency, defaultConcurrency)
+
+	return Func(func(ctx context.Context) ([]api.CheckPayload, error) {
+		return nagios.RunAll(ctx, cfg, plugins, concurrency), nil
+	}), nil
+}
+
+// PluginConfig is the configuration of a plugin check: a single plugin, a list
+// of plugins, a plugins file, or every plugin in the plugin directory
+type PluginConfig struct {
+	Plugin      string               `yaml:"plugin"` // name in dir, or path
+	Args        []string             `yaml:"args"`
+	Config      map[string]any       `yaml:"config"`
+	Plugins     []plugincheck.Plugin `yaml:"plugins"`
+	PluginsFile string               `yaml:"plugins_file"`
+	Dir         string               `yaml:"dir"`
+	Timeout     time.Duration        `yaml:"timeout"`
+	Concurrency int                  `yaml:"concurrency"`
+}
+
+func newPlugin(common Common, config *yaml.Node) (Checker, error) {
+	var c PluginConfig
+	if err := DecodeConfig(config, &c); err != nil {
+		return nil, err
+	}
+	return NewPlugin(common, c)
+}
+
+// NewPlugin creates a plugin Checker
+func NewPlugin(common Common, c PluginConfig) (Checker, error) {
+	dir := orDefault(c.Dir, plugincheck.DefaultDir)
+
+	plugins := c.Plugins
+	if c.Plugin != "" {
+		plugins = append([]plugincheck.Plugin{{Name: common.Name, Path: c.Plugin, Args: c.Args, Config: c.Config}}, plugins...)
+	}
+	for i := range plugins {
+		if plugins[i].Path == "" {
+			return nil, fmt.Errorf("plugin %d has no path", i+1)
+		}
+		var err error
+		if plugins[i].Path, err = plugincheck.Find(dir, plugins[i].Path); err != nil {
+			return nil, err
+		}
+	}
+	if c.PluginsFile != "" {
+		filePlugins, err := plugincheck.LoadPlugins(c.PluginsFile, dir)
+		if err != nil {
+			return nil, err
+		}
+		plugins = append(plugins, filePlugins...)
+	} else if len(plugins) == 0 {
+		paths, err := plugincheck.Discover(dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no plugins found in %s", dir)
+		}
+		for _, path := range paths {
+			plugins = append(plugins, plugincheck.Plugin{Path: path, Args: c.Args, Config: c.Config})
+		}
+	}
+
+	cfg := plugincheck.Config{
+		Dashboard:        common.Dashboard,
+		Site:             common.Site,
+		Service:          common.Service,
+		Message:          common.Message,
+		InactiveExpire:   common.InactiveExpire,
+		InactiveEscalate: common.InactiveEscalate,
+		Highlighted:      common.Highlighted,
+		Timeout:          c.Timeout,
+	}
+	if cfg.Service == "" {
+		var err error
+		if cfg.Service, err = hostname(); err != nil {
+			return nil, err
+		}
+	}
+	concurrency := orDefault(c.Concurrency, defaultConcurrency)
+
+	return Func(func(ctx context.Context) ([]api.CheckPayload, error) {
+		return plugincheck.RunAll(ctx, cfg, plugins, concurrency), nil
+	}), nil
+}
+
+// orDefault returns value, or def if value is zero
+func orDefault[T comparable](value, def T) T {
+	var zero T
+	if value == zero {
+		return def
+	}
+	return value
+}
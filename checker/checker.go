@@ -0,0 +1,181 @@
+// Package checker provides a common interface for check types and a registry to
+// create them by type name from configuration.
+package checker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/alertbingo/alertbingo/api"
+	"gopkg.in/yaml.v3"
+)
+
+// Checker runs a check and returns its results
+type Checker interface {
+	Run(ctx context.Context) ([]api.CheckPayload, error)
+}
+
+// Func adapts a function to the Checker interface
+type Func func(ctx context.Context) ([]api.CheckPayload, error)
+
+// Run calls f(ctx)
+func (f Func) Run(ctx context.Context) ([]api.CheckPayload, error) {
+	return f(ctx)
+}
+
+// Common holds the settings shared by every check type
+type Common struct {
+	Dashboard        string `yaml:"dashboard"`
+	Site             string `yaml:"site"`
+	Service          string `yaml:"service"`
+	Name             string `yaml:"name"`
+	Message          string `yaml:"message"`
+	InactiveExpire   string `yaml:"inactive_expire"`
+	InactiveEscalate string `yaml:"inactive_escalate"`
+	Highlighted      string `yaml:"highlighted"`
+}
+
+// withDefaults returns c with its empty fields filled from defaults
+func (c Common) withDefaults(defaults Common) Common {
+	for _, f := range []struct {
+		dst *string
+		def string
+	}{
+		{&c.Dashboard, defaults.Dashboard},
+		{&c.Site, defaults.Site},
+		{&c.Service, defaults.Service},
+		{&c.Name, defaults.Name},
+		{&c.Message, defaults.Message},
+		{&c.InactiveExpire, defaults.InactiveExpire},
+		{&c.InactiveEscalate, defaults.InactiveEscalate},
+		{&c.Highlighted, defaults.Highlighted},
+	} {
+		if *f.dst == "" {
+			*f.dst = f.def
+		}
+	}
+	return c
+}
+
+// Factory creates a Checker from the common settings and the type's own
+// configuration, which it decodes from config, e.g. with DecodeConfig
+type Factory func(common Common, config *yaml.Node) (Checker, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{
+		"hoststats": newHostStats,
+		"urlcheck":  newURLCheck,
+		"certcheck": newCertCheck,
+		"exec":      newExec,
+		"nagios":    newNagios,
+		"plugin":    newPlugin,
+	}
+)
+
+// Register makes a check type available by name. It panics if the name is
+// already registered.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("checker: type %q already registered", name))
+	}
+	registry[name] = factory
+}
+
+// Types returns the registered type names in order
+func Types() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	types := make([]string, 0, len(registry))
+	for name := range registry {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// New creates a Checker of the named type
+func New(typeName string, common Common, config *yaml.Node) (Checker, error) {
+	registryMu.RLock()
+	factory, ok := registry[typeName]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown check type: %s", typeName)
+	}
+	if config == nil {
+		config = &yaml.Node{Kind: yaml.MappingNode}
+	}
+
+	checker, err := factory(common, config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", typeName, err)
+	}
+	return checker, nil
+}
+
+// DecodeConfig decodes a check type's configuration into out, which must be a
+// pointer to a struct, and rejects keys that out has no field for, so that a
+// misspelt or unsupported setting is an error rather than ignored.
+func DecodeConfig(config *yaml.Node, out any) error {
+	if err := config.Decode(out); err != nil {
+		return err
+	}
+
+	// A node can't be decoded strictly, so decode its YAML again with KnownFields
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(reflect.New(reflect.TypeOf(out).Elem()).Interface()); err != nil && !errors.Is(err, io.EOF) {
+		return unknownFieldsError(err)
+	}
+	return nil
+}
+
+// unknownFieldPattern matches yaml's unknown field errors, whose line numbers
+// refer to the re-encoded configuration rather than the manifest
+var unknownFieldPattern = regexp.MustCompile(`^line \d+: field (\S+) not found in type .+$`)
+
+// unknownFieldsError rewrites a strict decoding error as a list of unknown settings
+func unknownFieldsError(err error) error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return err
+	}
+
+	var fields []string
+	for _, e := range typeErr.Errors {
+		if m := unknownFieldPattern.FindStringSubmatch(e); m != nil {
+			fields = append(fields, m[1])
+		}
+	}
+	if len(fields) == 0 {
+		return err
+	}
+	return fmt.Errorf("unknown setting: %s", strings.Join(fields, ", "))
+}
+
+// hostname returns the default service for checks of the local machine
+func hostname() (string, error) {
+	name, err := os.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("failed to get hostname: %w", err)
+	}
+	return name, nil
+}
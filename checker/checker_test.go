@@ -0,0 +1,297 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alertbingo/alertbingo/api"
+	"gopkg.in/yaml.v3"
+)
+
+// staticConfig is the configuration of the test "static" check type
+type staticConfig struct {
+	Level int    `yaml:"level"`
+	Fail  string `yaml:"fail"`
+}
+
+var registerStatic sync.Once
+
+// registerStaticType registers the "static" check type, which reports a fixed
+// level or fails with a fixed error
+func registerStaticType() {
+	registerStatic.Do(func() { Register("static", newStatic) })
+}
+
+func newStatic(common Common, config *yaml.Node) (Checker, error) {
+	var c staticConfig
+	if err := config.Decode(&c); err != nil {
+		return nil, err
+	}
+
+	return Func(func(ctx context.Context) ([]api.CheckPayload, error) {
+		if c.Fail != "" {
+			return nil, errors.New(c.Fail)
+		}
+		return []api.CheckPayload{{
+			Dashboard:  common.Dashboard,
+			Site:       common.Site,
+			Service:    common.Service,
+			Name:       common.Name,
+			AlertLevel: c.Level,
+			Message:    common.Message,
+			Value:      "static",
+		}}, nil
+	}), nil
+}
+
+func writeManifest(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "checks.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRegistry(t *testing.T) {
+	registerStaticType()
+
+	types := Types()
+	for _, name := range []string{"certcheck", "exec", "hoststats", "nagios", "plugin", "static", "urlcheck"} {
+		if !slices.Contains(types, name) {
+			t.Errorf("Types() = %v, missing %s", types, name)
+		}
+	}
+	if !slices.IsSorted(types) {
+		t.Errorf("Types() = %v, want sorted", types)
+	}
+
+	if _, err := New("missing", Common{}, nil); err == nil || !strings.Contains(err.Error(), "unknown check type") {
+		t.Errorf("New(missing) error = %v, want unknown check type", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Register with a duplicate name did not panic")
+		}
+	}()
+	Register("static", nil)
+}
+
+func TestNew_Builtin(t *testing.T) {
+	tests := []struct {
+		typeName string
+		common   Common
+		config   string
+		wantErr  string
+	}{
+		{"hoststats", Common{Service: "web-1"}, "{}", ""},
+		{"hoststats", Common{}, "{}", "service is required"},
+		{"hoststats", Common{Service: "web-1"}, "interval: 5m", "unknown setting: interval"},
+		{"urlcheck", Common{Name: "http"}, "targets: [{url: 'https://example.com'}]", ""},
+		{"urlcheck", Common{Name: "http"}, "{}", "targets or scenarios are required"},
+		{"urlcheck", Common{}, "targets: [{url: 'https://example.com'}]", "name is required"},
+		{
+			typeName: "urlcheck",
+			common:   Common{Name: "http"},
+			config: "targets: [{url: 'https://example.com'}]\ninsecure_skip_verify: true\nbody_limit: 1024\n" +
+				"proxy: {url: 'http://proxy:3128', no_proxy: .internal}\nresolve: ['example.com:443:127.0.0.1']\nslow_ttfb: 1s",
+		},
+		{"urlcheck", Common{Name: "http"}, "targets: [{url: 'https://example.com', expect_code: 200}]", "unknown setting: expect_code"},
+		{"urlcheck", Common{Name: "http"}, "targets: [{url: 'https://example.com'}]\nverify: false\nretries: 2", "unknown setting: verify, retries"},
+		{"urlcheck", Common{Name: "http"}, "targets: [{url: 'https://example.com'}]\nipv4: true\nipv6: true", "cannot be used together"},
+		{"urlcheck", Common{Name: "http"}, "targets: [{url: 'https://example.com'}]\nca_file: /missing/ca.pem", "/missing/ca.pem"},
+		{"certcheck", Common{Name: "tls"}, "urls: [example.com]", ""},
+		{
+			typeName: "certcheck",
+			common:   Common{Name: "tls"},
+			config:   "urls: [example.com]\ninsecure_skip_verify: true\nsni: www.example.com\npins: ['" + strings.Repeat("ab", 32) + "']\npolicy: {min_tls: '1.1', level: warn}",
+		},
+		{"certcheck", Common{Name: "tls"}, "urls: [example.com]\npins: [sha256/abc]", "invalid pin"},
+		{"certcheck", Common{Name: "tls"}, "urls: [example.com]\npolicy: {min_tls: '1.4'}", "invalid TLS version"},
+		{"certcheck", Common{Name: "tls"}, "urls: [example.com]\nwarn_days: 30", "unknown setting: warn_days"},
+		{"exec", Common{Name: "backup"}, "command: [true]\nexit_levels: ['1=warn']", ""},
+		{"exec", Common{Name: "backup"}, "command: [true]\nexit_level: ['1=warn']", "unknown setting: exit_level"},
+		{"exec", Common{Name: "backup"}, "command: [true]\nexit_levels: ['1=critical']", "invalid alert level"},
+		{"nagios", Common{Service: "db"}, "plugins: [{name: load}]", "plugin 1 has no command"},
+		{"nagios", Common{Service: "db"}, "{}", "command, plugins or plugins_file are required"},
+		{"nagios", Common{Service: "db"}, "plugins_file: /missing/plugins.yaml", "failed to read plugins file"},
+		{"plugin", Common{Service: "db"}, "plugins_file: /missing/plugins.yaml", "failed to read plugins file"},
+		{"plugin", Common{Service: "db"}, "plugin: missing\ndir: " + t.TempDir(), "plugin missing not found"},
+		{"plugin", Common{Service: "db"}, "dir: " + t.TempDir(), "no plugins found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typeName+"/"+tt.wantErr, func(t *testing.T) {
+			var node yaml.Node
+			if err := yaml.Unmarshal([]byte(tt.config), &node); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := New(tt.typeName, tt.common, node.Content[0])
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("New() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("New() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadManifest_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"no checks", "defaults: {dashboard: D}\n", "has no checks"},
+		{"no type", "checks:\n  - name: x\n", "has no type"},
+		{"invalid yaml", "checks: [\n", "failed to parse manifest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadManifest(writeManifest(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadManifest() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunner(t *testing.T) {
+	registerStaticType()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	path := writeManifest(t, `
+defaults:
+  site: S
+  service: svc
+checks:
+  - type: static
+    name: first
+    level: 1
+    message: "{{ .Level }} from {{ .Service }}"
+  - type: static
+    dashboard: Other
+    name: second
+    fail: boom
+  - type: static
+    service: ""
+    fail: boom
+  - type: urlcheck
+    name: http
+    targets:
+      - url: `+server.URL+`
+`)
+
+	manifest, err := LoadManifest(path)
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+	runner, err := NewRunner(manifest, Common{Dashboard: "D", Site: "ignored"})
+	if err != nil {
+		t.Fatalf("NewRunner() error = %v", err)
+	}
+
+	checks := runner.Run(context.Background(), 2)
+	if len(checks) != 4 {
+		t.Fatalf("Run() returned %d checks, want 4: %+v", len(checks), checks)
+	}
+
+	first := checks[0]
+	if first.Dashboard != "D" || first.Site != "S" || first.Service != "svc" || first.Name != "first" {
+		t.Errorf("first check = %+v, want defaults merged", first)
+	}
+	if first.Message != "warn from svc" || first.AlertLevel != 1 {
+		t.Errorf("first check message = %q level %d, want rendered template", first.Message, first.AlertLevel)
+	}
+
+	second := checks[1]
+	if second.Dashboard != "Other" || second.AlertLevel != 2 || second.Message != "boom" || second.Value != "Error" {
+		t.Errorf("failed check = %+v, want alert with error message", second)
+	}
+
+	// An empty service falls back to the manifest default, so only the name is the type
+	if checks[2].Service != "svc" || checks[2].Name != "static" {
+		t.Errorf("unnamed failed check = %+v, want name static", checks[2])
+	}
+
+	if checks[3].Name != "http" || checks[3].Service != server.URL || checks[3].AlertLevel != 0 {
+		t.Errorf("urlcheck = %+v, want OK result for %s", checks[3], server.URL)
+	}
+
+	if err := api.ValidateChecks(checks); err != nil {
+		t.Errorf("ValidateChecks() error = %v", err)
+	}
+}
+
+func TestNewRunner_InvalidCheck(t *testing.T) {
+	registerStaticType()
+
+	manifest, err := LoadManifest(writeManifest(t, "checks:\n  - type: static\n  - type: exec\n    name: x\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewRunner(manifest, Common{})
+	if err == nil || err.Error() != "check 2: exec: command is required" {
+		t.Errorf("NewRunner() error = %v, want check 2: exec: command is required", err)
+	}
+}
+
+func TestNewRunner_UnknownSetting(t *testing.T) {
+	manifest, err := LoadManifest(writeManifest(t, `
+checks:
+  - type: urlcheck
+    name: http
+    message: "{{ .Name }}"
+    value: "{{ .Value }}"
+    highlighted: "true"
+    targets:
+      - url: https://example.com
+    timout: 5s
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewRunner(manifest, Common{})
+	if err == nil || err.Error() != "check 1: urlcheck: unknown setting: timout" {
+		t.Errorf("NewRunner() error = %v, want check 1: urlcheck: unknown setting: timout", err)
+	}
+}
+
+func TestExec_RunCommand(t *testing.T) {
+	job, err := NewExec(Common{Service: "db", Name: "backup"}, ExecConfig{
+		Command:    []string{"sh", "-c", "exit 3"},
+		ExitLevels: []string{"3=warn"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, check := job.RunCommand(context.Background())
+	if result.ExitCode != 3 {
+		t.Errorf("ExitCode = %d, want 3", result.ExitCode)
+	}
+	if check.AlertLevel != 1 {
+		t.Errorf("AlertLevel = %d, want 1", check.AlertLevel)
+	}
+}
@@ -0,0 +1,177 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/alertbingo/alertbingo/api"
+	"github.com/alertbingo/alertbingo/checktemplate"
+	"github.com/alertbingo/alertbingo/internal/checkutil"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is a list of checks to run, with defaults for their common settings
+type Manifest struct {
+	Defaults Common  `yaml:"defaults"`
+	Checks   []Entry `yaml:"checks"`
+}
+
+// Entry is a check in a manifest. Its type's own settings sit alongside the
+// common settings in the same mapping.
+type Entry struct {
+	Type string
+	Common
+	Value  string    // value template
+	Config yaml.Node // the type's own settings, decoded by the type's factory
+}
+
+// entryKeys are the settings of an entry that aren't passed to its type's factory
+var entryKeys = map[string]bool{
+	"type":              true,
+	"dashboard":         true,
+	"site":              true,
+	"service":           true,
+	"name":              true,
+	"message":           true,
+	"value":             true,
+	"inactive_expire":   true,
+	"inactive_escalate": true,
+	"highlighted":       true,
+}
+
+// UnmarshalYAML decodes the type and common settings and keeps the rest of the
+// node for the type's factory
+func (e *Entry) UnmarshalYAML(node *yaml.Node) error {
+	var fields struct {
+		Type   string `yaml:"type"`
+		Common `yaml:",inline"`
+		Value  string `yaml:"value"`
+	}
+	if err := node.Decode(&fields); err != nil {
+		return err
+	}
+
+	config := *node
+	config.Content = nil
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if !entryKeys[node.Content[i].Value] {
+			config.Content = append(config.Content, node.Content[i], node.Content[i+1])
+		}
+	}
+
+	e.Type, e.Common, e.Value, e.Config = fields.Type, fields.Common, fields.Value, config
+	return nil
+}
+
+// LoadManifest reads a manifest from a YAML file
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if len(manifest.Checks) == 0 {
+		return nil, fmt.Errorf("manifest %s has no checks", path)
+	}
+	for i, entry := range manifest.Checks {
+		if entry.Type == "" {
+			return nil, fmt.Errorf("check %d in %s has no type", i+1, path)
+		}
+	}
+
+	return &manifest, nil
+}
+
+// job is a manifest entry's checker, ready to run
+type job struct {
+	typeName  string
+	common    Common
+	checker   Checker
+	templates *checktemplate.Templates
+}
+
+// Runner runs the checks of a manifest
+type Runner struct {
+	jobs []job
+}
+
+// NewRunner creates the checkers for a manifest's checks, failing if any check
+// is misconfigured. Settings that neither a check nor the manifest's defaults
+// set are taken from defaults, e.g. from command line flags.
+func NewRunner(manifest *Manifest, defaults Common) (*Runner, error) {
+	defaults = manifest.Defaults.withDefaults(defaults)
+
+	var r Runner
+	for i, entry := range manifest.Checks {
+		common := entry.Common.withDefaults(defaults)
+
+		templates, err := checktemplate.Parse(common.Message, entry.Value)
+		if err != nil {
+			return nil, fmt.Errorf("check %d: %w", i+1, err)
+		}
+		if checktemplate.IsTemplate(common.Message) {
+			// Rendered after the check runs
+			common.Message = ""
+		}
+
+		checker, err := New(entry.Type, common, &entry.Config)
+		if err != nil {
+			return nil, fmt.Errorf("check %d: %w", i+1, err)
+		}
+		r.jobs = append(r.jobs, job{typeName: entry.Type, common: common, checker: checker, templates: templates})
+	}
+
+	return &r, nil
+}
+
+// Run runs the checks with at most workers running at once, and returns their
+// results in manifest order. A check that fails to run is reported as an alert.
+func (r *Runner) Run(ctx context.Context, workers int) []api.CheckPayload {
+	results := make([][]api.CheckPayload, len(r.jobs))
+	checkutil.ForEach(len(r.jobs), workers, func(i int) {
+		results[i] = r.jobs[i].run(ctx)
+	})
+
+	var checks []api.CheckPayload
+	for _, result := range results {
+		checks = append(checks, result...)
+	}
+	return checks
+}
+
+func (j job) run(ctx context.Context) []api.CheckPayload {
+	checks, err := j.checker.Run(ctx)
+	if err != nil {
+		checks = []api.CheckPayload{errorPayload(j.typeName, j.common, err)}
+	}
+	return j.templates.Apply(checks)
+}
+
+// errorPayload creates an alert for a check that failed to run, named after its
+// type if it has no service or name
+func errorPayload(typeName string, common Common, err error) api.CheckPayload {
+	payload := api.CheckPayload{
+		Dashboard:        common.Dashboard,
+		Site:             common.Site,
+		Service:          common.Service,
+		Name:             common.Name,
+		AlertLevel:       2, // alert
+		Value:            "Error",
+		Message:          checkutil.AppendReason(common.Message, err.Error()),
+		InactiveExpire:   common.InactiveExpire,
+		InactiveEscalate: common.InactiveEscalate,
+		Highlighted:      common.Highlighted,
+	}
+	if payload.Service == "" {
+		payload.Service = typeName
+	}
+	if payload.Name == "" {
+		payload.Name = typeName
+	}
+	return payload
+}
@@ -0,0 +1,232 @@
+// Package checktemplate renders check messages and values from text/template strings.
+package checktemplate
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/alertbingo/alertbingo/api"
+	"github.com/alertbingo/alertbingo/internal/checkutil"
+)
+
+// Data is the data available to message and value templates
+type Data struct {
+	Dashboard  string
+	Site       string
+	Service    string
+	Name       string
+	AlertLevel int            // 0 ok, 1 warn, 2 alert
+	Level      string         // ok, warn or alert
+	Value      string         // the check's value before templating
+	Raw        any            // the measured value, e.g. a percentage or time.Duration
+	Values     map[string]any // other measurements, e.g. .Values.used
+	Thresholds map[string]any // limits the value is compared against, e.g. .Thresholds.warn
+	URL        string         // the URL or file checked, if any
+	Host       string         // hostname of the machine running the check
+	Time       time.Time      // when the template was rendered
+}
+
+// levelNames are the alert level names by level
+var levelNames = []string{"ok", "warn", "alert"}
+
+// Templates holds the parsed message and value templates
+type Templates struct {
+	message *template.Template
+	value   *template.Template
+	now     func() time.Time
+}
+
+// IsTemplate reports whether s contains template actions
+func IsTemplate(s string) bool {
+	return strings.Contains(s, "{{")
+}
+
+// Parse parses the message and value templates. The message is only parsed if
+// it contains template actions, so a plain message keeps its usual handling;
+// any non-empty value is parsed. Parse returns nil if there's nothing to render.
+func Parse(message, value string) (*Templates, error) {
+	t := &Templates{now: time.Now}
+
+	if IsTemplate(message) {
+		tmpl, err := template.New("message").Funcs(funcs).Parse(message)
+		if err != nil {
+			return nil, fmt.Errorf("invalid message template: %w", err)
+		}
+		t.message = tmpl
+	}
+	if value != "" {
+		tmpl, err := template.New("value").Funcs(funcs).Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value template: %w", err)
+		}
+		t.value = tmpl
+	}
+
+	if t.message == nil && t.value == nil {
+		return nil, nil
+	}
+	return t, nil
+}
+
+// Apply renders the templates for each check. A rendered message is followed by
+// the check's own alert reasons, as a plain message is. If a template fails to
+// render, the error is added to the message rather than losing the check.
+func (t *Templates) Apply(checks []api.CheckPayload) []api.CheckPayload {
+	if t == nil {
+		return checks
+	}
+
+	host, _ := os.Hostname()
+	now := t.now()
+
+	for i := range checks {
+		check := &checks[i]
+		data := newData(*check, host, now)
+
+		if t.value != nil {
+			value, err := render(t.value, data)
+			if err != nil {
+				check.Message = checkutil.AppendReason(check.Message, err.Error())
+			} else {
+				check.Value = value
+			}
+		}
+		if t.message != nil {
+			message, err := render(t.message, data)
+			if err != nil {
+				message = err.Error()
+			}
+			check.Message = checkutil.AppendReason(message, check.Message)
+		}
+	}
+
+	return checks
+}
+
+func newData(check api.CheckPayload, host string, now time.Time) Data {
+	data := Data{
+		Dashboard:  check.Dashboard,
+		Site:       check.Site,
+		Service:    check.Service,
+		Name:       check.Name,
+		AlertLevel: check.AlertLevel,
+		Value:      check.Value,
+		Host:       host,
+		Time:       now,
+	}
+	if check.AlertLevel >= 0 && check.AlertLevel < len(levelNames) {
+		data.Level = levelNames[check.AlertLevel]
+	}
+	if m := check.Measurement; m != nil {
+		data.Raw = m.Raw
+		data.Values = m.Values
+		data.Thresholds = m.Thresholds
+		data.URL = m.Target
+	}
+	return data
+}
+
+func render(tmpl *template.Template, data Data) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("%s template error: %w", tmpl.Name(), err)
+	}
+	return sb.String(), nil
+}
+
+// funcs are the helper functions available to templates
+var funcs = template.FuncMap{
+	"bytes":    humanBytes,
+	"duration": humanDuration,
+}
+
+// humanBytes formats a number of bytes with binary units, e.g. 1.5 GiB
+func humanBytes(v any) (string, error) {
+	n, err := toFloat(v)
+	if err != nil {
+		return "", err
+	}
+
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+	i := 0
+	for math.Abs(n) >= 1024 && i < len(units)-1 {
+		n /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%.0f %s", n, units[i]), nil
+	}
+	return fmt.Sprintf("%s %s", strings.TrimSuffix(fmt.Sprintf("%.1f", n), ".0"), units[i]), nil
+}
+
+// humanDuration formats a time.Duration, or a number of seconds, with its two
+// largest units, e.g. 3d 4h or 2m 5s
+func humanDuration(v any) (string, error) {
+	d, ok := v.(time.Duration)
+	if !ok {
+		seconds, err := toFloat(v)
+		if err != nil {
+			return "", err
+		}
+		d = time.Duration(seconds * float64(time.Second))
+	}
+
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	if d < time.Second {
+		return sign + d.Round(time.Millisecond).String(), nil
+	}
+
+	units := []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+	var parts []string
+	for _, u := range units {
+		if d >= u.size || len(parts) > 0 {
+			parts = append(parts, fmt.Sprintf("%d%s", d/u.size, u.suffix))
+			d %= u.size
+		}
+		if len(parts) == 2 {
+			break
+		}
+	}
+	return sign + strings.Join(parts, " "), nil
+}
+
+// toFloat converts a number to float64
+func toFloat(v any) (float64, error) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), nil
+	case int32:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case uint:
+		return float64(n), nil
+	case uint32:
+		return float64(n), nil
+	case uint64:
+		return float64(n), nil
+	case float32:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	case time.Duration:
+		return n.Seconds(), nil
+	default:
+		return 0, fmt.Errorf("not a number: %v", v)
+	}
+}
@@ -0,0 +1,133 @@
+package checktemplate
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alertbingo/alertbingo/api"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		value     string
+		wantNil   bool
+		wantError bool
+	}{
+		{"nothing", "", "", true, false},
+		{"plain message", "Production database", "", true, false},
+		{"message template", "{{.Host}}", "", false, false},
+		{"plain value", "", "up", false, false},
+		{"invalid message", "{{.Host", "", false, true},
+		{"unknown function", "", "{{humanize .Raw}}", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templates, err := Parse(tt.message, tt.value)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("Parse() error = %v, wantError %v", err, tt.wantError)
+			}
+			if err == nil && (templates == nil) != tt.wantNil {
+				t.Errorf("Parse() = %v, wantNil %v", templates, tt.wantNil)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	host, _ := os.Hostname()
+	now := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	templates, err := Parse(
+		`{{.Level}} on {{.Host}} at {{.Time.Format "15:04"}}: {{.Values.used | bytes}} of {{.Values.total | bytes}} (warn at {{.Thresholds.warn}}%)`,
+		`{{printf "%.1f" .Raw}}%`,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	templates.now = func() time.Time { return now }
+
+	checks := []api.CheckPayload{
+		{
+			Name:       "Memory",
+			AlertLevel: 1,
+			Value:      "97%",
+			Message:    "Memory % over 95",
+			Measurement: &api.Measurement{
+				Raw:        96.52,
+				Values:     map[string]any{"used": uint64(7 << 30), "total": uint64(8 << 30)},
+				Thresholds: map[string]any{"warn": 95},
+			},
+		},
+		{Name: "Uptime", Value: "3d"},
+	}
+
+	checks = templates.Apply(checks)
+
+	want := "warn on " + host + " at 12:30: 7 GiB of 8 GiB (warn at 95%) - Memory % over 95"
+	if checks[0].Message != want {
+		t.Errorf("Message = %q, want %q", checks[0].Message, want)
+	}
+	if checks[0].Value != "96.5%" {
+		t.Errorf("Value = %q, want %q", checks[0].Value, "96.5%")
+	}
+
+	// A check without the values reports the template error in its message
+	if !strings.HasPrefix(checks[1].Message, "message template error") || !strings.Contains(checks[1].Message, "not a number") {
+		t.Errorf("expected message template error, got %q", checks[1].Message)
+	}
+}
+
+func TestApply_Nil(t *testing.T) {
+	var templates *Templates
+	checks := []api.CheckPayload{{Name: "a", Message: "m"}}
+	if got := templates.Apply(checks); got[0].Message != "m" {
+		t.Errorf("expected checks unchanged, got %+v", got)
+	}
+}
+
+func TestHumanBytes(t *testing.T) {
+	tests := []struct {
+		input any
+		want  string
+	}{
+		{512, "512 B"},
+		{int64(1536), "1.5 KiB"},
+		{uint64(5 << 30), "5 GiB"},
+		{3.5 * (1 << 40), "3.5 TiB"},
+	}
+
+	for _, tt := range tests {
+		if got, err := humanBytes(tt.input); err != nil || got != tt.want {
+			t.Errorf("humanBytes(%v) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+
+	if _, err := humanBytes("lots"); err == nil {
+		t.Error("expected error for a non-number")
+	}
+}
+
+func TestHumanDuration(t *testing.T) {
+	tests := []struct {
+		input any
+		want  string
+	}{
+		{850 * time.Millisecond, "850ms"},
+		{125 * time.Second, "2m 5s"},
+		{76*time.Hour + 30*time.Minute, "3d 4h"},
+		{2 * time.Hour, "2h 0m"},
+		{90, "1m 30s"},
+		{-3 * time.Hour, "-3h 0m"},
+	}
+
+	for _, tt := range tests {
+		if got, err := humanDuration(tt.input); err != nil || got != tt.want {
+			t.Errorf("humanDuration(%v) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+}
@@ -2,16 +2,24 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
-	"strconv"
+	"os/signal"
+	"slices"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/alertbingo/alertbingo/api"
-	"github.com/alertbingo/alertbingo/certcheck"
-	"github.com/alertbingo/alertbingo/hoststats"
+	"github.com/alertbingo/alertbingo/checker"
+	"github.com/alertbingo/alertbingo/checktemplate"
+	"github.com/alertbingo/alertbingo/execcheck"
+	"github.com/alertbingo/alertbingo/nagios"
+	"github.com/alertbingo/alertbingo/plugincheck"
+	"github.com/alertbingo/alertbingo/proxyconfig"
+	"github.com/alertbingo/alertbingo/tlsconfig"
 	"github.com/alertbingo/alertbingo/urlcheck"
 	"github.com/urfave/cli/v3"
 )
@@ -27,80 +35,34 @@ func main() {
 			{
 				Name:  "hoststats",
 				Usage: "Send host statistics checks (memory, uptime, CPU) to Alert Bingo",
-				Flags: []cli.Flag{
-					&cli.StringFlag{
-						Name:     "dashboard",
-						Aliases:  []string{"d"},
-						Usage:    "Dashboard name",
-						Sources:  cli.EnvVars("ALERTBINGO_DASHBOARD"),
-						Required: true,
-					},
-					&cli.StringFlag{
-						Name:     "site",
-						Aliases:  []string{"s"},
-						Usage:    "Site identifier (e.g., myapp-prod)",
-						Sources:  cli.EnvVars("ALERTBINGO_SITE"),
-						Required: true,
-					},
-					&cli.StringFlag{
-						Name:     "service",
-						Usage:    "Service name (e.g., host)",
-						Sources:  cli.EnvVars("ALERTBINGO_SERVICE"),
-						Required: true,
+				Flags: commonFlags(commonOptions{
+					omit:     []string{"name"},
+					required: []string{"dashboard", "site", "service"},
+					usage: map[string]string{
+						"service": "Service name (e.g., host)",
 					},
-					&cli.StringFlag{
-						Name:    "message",
-						Aliases: []string{"m"},
-						Usage:   "Optional long-form status message",
-						Sources: cli.EnvVars("ALERTBINGO_MESSAGE"),
-					},
-					&cli.StringFlag{
-						Name:    "inactive-expire",
-						Usage:   "Optional duration string for inactive expiry (e.g., 48h or 30m)",
-						Sources: cli.EnvVars("ALERTBINGO_INACTIVE_EXPIRE"),
-					},
-					&cli.StringFlag{
-						Name:    "inactive-escalate",
-						Usage:   "Optional duration string for inactive escalation (e.g., 1h or 30m)",
-						Sources: cli.EnvVars("ALERTBINGO_INACTIVE_ESCALATE"),
-					},
-					&cli.StringFlag{
-						Name:    "highlighted",
-						Usage:   "Optional highlighted status",
-						Sources: cli.EnvVars("ALERTBINGO_HIGHLIGHTED"),
-					},
-					&cli.StringFlag{
-						Name:     "token",
-						Aliases:  []string{"t"},
-						Usage:    "API Bearer token",
-						Sources:  cli.EnvVars("ALERTBINGO_TOKEN"),
-						Required: true,
-					},
-					&cli.StringFlag{
-						Name:    "api-url",
-						Usage:   "API URL",
-						Sources: cli.EnvVars("ALERTBINGO_API_URL"),
-						Value:   "https://app.alert.bingo/api/v1/checks",
-					},
-				},
+				}),
 				Action: func(ctx context.Context, cmd *cli.Command) error {
-					cfg := hoststats.Config{
-						Dashboard:        cmd.String("dashboard"),
-						Site:             cmd.String("site"),
-						Service:          cmd.String("service"),
-						Message:          cmd.String("message"),
-						InactiveExpire:   cmd.String("inactive-expire"),
-						InactiveEscalate: cmd.String("inactive-escalate"),
-						Highlighted:      cmd.String("highlighted"),
+					message, templates, err := messageTemplates(cmd)
+					if err != nil {
+						return err
 					}
 
-					checks, err := hoststats.Collect(ctx, cfg)
+					check, err := checker.New("hoststats", commonSettings(cmd, message), nil)
+					if err != nil {
+						return err
+					}
+
+					checks, err := check.Run(ctx)
 					if err != nil {
 						return fmt.Errorf("failed to collect host stats: %w", err)
 					}
 
-					client := api.NewClient(cmd.String("api-url"), cmd.String("token"))
-					responses, err := client.SendChecks(ctx, checks)
+					client, err := newAPIClient(cmd)
+					if err != nil {
+						return err
+					}
+					responses, err := client.SendChecks(ctx, templates.Apply(checks))
 					if err != nil {
 						return err
 					}
@@ -118,34 +80,14 @@ func main() {
 			{
 				Name:  "check",
 				Usage: "Send a check to Alert Bingo",
-				Flags: []cli.Flag{
-					&cli.StringFlag{
-						Name:     "dashboard",
-						Aliases:  []string{"d"},
-						Usage:    "Dashboard name",
-						Sources:  cli.EnvVars("ALERTBINGO_DASHBOARD"),
-						Required: true,
-					},
-					&cli.StringFlag{
-						Name:     "site",
-						Aliases:  []string{"s"},
-						Usage:    "Site identifier (e.g., myapp-prod)",
-						Sources:  cli.EnvVars("ALERTBINGO_SITE"),
-						Required: true,
-					},
-					&cli.StringFlag{
-						Name:     "service",
-						Usage:    "Service name (e.g., postgres)",
-						Sources:  cli.EnvVars("ALERTBINGO_SERVICE"),
-						Required: true,
-					},
-					&cli.StringFlag{
-						Name:     "name",
-						Aliases:  []string{"n"},
-						Usage:    "Check name (e.g., postgres-rds-space-free)",
-						Sources:  cli.EnvVars("ALERTBINGO_NAME"),
-						Required: true,
+				Flags: append(commonFlags(commonOptions{
+					omit:     []string{"value"},
+					required: []string{"dashboard", "site", "service", "name"},
+					usage: map[string]string{
+						"service": "Service name (e.g., postgres)",
+						"name":    "Check name (e.g., postgres-rds-space-free)",
 					},
+				}),
 					&cli.StringFlag{
 						Name:    "alert-level",
 						Aliases: []string{"l"},
@@ -153,68 +95,41 @@ func main() {
 						Sources: cli.EnvVars("ALERTBINGO_ALERT_LEVEL"),
 						Value:   "ok",
 					},
-					&cli.StringFlag{
-						Name:    "message",
-						Aliases: []string{"m"},
-						Usage:   "Optional long-form status message",
-						Sources: cli.EnvVars("ALERTBINGO_MESSAGE"),
-					},
 					&cli.StringFlag{
 						Name:    "value",
 						Aliases: []string{"v"},
-						Usage:   "Short-form status value",
+						Usage:   "Short-form status value; may be a template (e.g. \"{{.Host}}\")",
 						Sources: cli.EnvVars("ALERTBINGO_VALUE"),
 					},
-					&cli.StringFlag{
-						Name:    "inactive-expire",
-						Usage:   "Optional duration string for inactive expiry (e.g., 48h or 30m)",
-						Sources: cli.EnvVars("ALERTBINGO_INACTIVE_EXPIRE"),
-					},
-					&cli.StringFlag{
-						Name:    "inactive-escalate",
-						Usage:   "Optional duration string for inactive escalation (e.g., 1h or 30m)",
-						Sources: cli.EnvVars("ALERTBINGO_INACTIVE_ESCALATE"),
-					},
-					&cli.StringFlag{
-						Name:    "highlighted",
-						Usage:   "Optional highlighted status",
-						Sources: cli.EnvVars("ALERTBINGO_HIGHLIGHTED"),
-					},
-					&cli.StringFlag{
-						Name:     "token",
-						Aliases:  []string{"t"},
-						Usage:    "API Bearer token",
-						Sources:  cli.EnvVars("ALERTBINGO_TOKEN"),
-						Required: true,
-					},
-					&cli.StringFlag{
-						Name:    "api-url",
-						Usage:   "API URL",
-						Sources: cli.EnvVars("ALERTBINGO_API_URL"),
-						Value:   "https://app.alert.bingo/api/v1/checks",
-					},
-				},
+				),
 				Action: func(ctx context.Context, cmd *cli.Command) error {
 					alertLevel, err := api.ParseAlertLevel(cmd.String("alert-level"))
 					if err != nil {
 						return err
 					}
 
+					message, templates, err := messageTemplates(cmd)
+					if err != nil {
+						return err
+					}
+
 					payload := api.CheckPayload{
 						Dashboard:        cmd.String("dashboard"),
 						Site:             cmd.String("site"),
 						Service:          cmd.String("service"),
 						Name:             cmd.String("name"),
 						AlertLevel:       alertLevel,
-						Message:          cmd.String("message"),
-						Value:            cmd.String("value"),
+						Message:          message,
 						InactiveExpire:   cmd.String("inactive-expire"),
 						InactiveEscalate: cmd.String("inactive-escalate"),
 						Highlighted:      cmd.String("highlighted"),
 					}
 
-					client := api.NewClient(cmd.String("api-url"), cmd.String("token"))
-					responses, err := client.SendChecks(ctx, []api.CheckPayload{payload})
+					client, err := newAPIClient(cmd)
+					if err != nil {
+						return err
+					}
+					responses, err := client.SendChecks(ctx, templates.Apply([]api.CheckPayload{payload}))
 					if err != nil {
 						return err
 					}
@@ -229,93 +144,236 @@ func main() {
 					return nil
 				},
 			},
+			{
+				Name:      "submit",
+				Usage:     "Send many checks at once from a JSON array or NDJSON stream",
+				ArgsUsage: "[file|-]",
+				Flags: append(commonFlags(commonOptions{
+					defaults: true,
+					omit:     []string{"name", "message", "value"},
+					usage: map[string]string{
+						"service": "Service name for checks that don't set one (e.g., postgres)",
+					},
+				}),
+					&cli.IntFlag{
+						Name:    "batch-size",
+						Usage:   "Maximum number of checks per API request",
+						Sources: cli.EnvVars("ALERTBINGO_BATCH_SIZE"),
+						Value:   api.DefaultBatchSize,
+					},
+				),
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					input := os.Stdin
+					if path := cmd.Args().First(); path != "" && path != "-" {
+						f, err := os.Open(path)
+						if err != nil {
+							return fmt.Errorf("failed to open checks file: %w", err)
+						}
+						defer f.Close()
+						input = f
+					}
+
+					checks, err := api.ReadChecks(input)
+					if err != nil {
+						return err
+					}
+					if len(checks) == 0 {
+						return fmt.Errorf("no checks to submit")
+					}
+
+					checks = api.ApplyDefaults(checks, api.CheckPayload{
+						Dashboard:        cmd.String("dashboard"),
+						Site:             cmd.String("site"),
+						Service:          cmd.String("service"),
+						InactiveExpire:   cmd.String("inactive-expire"),
+						InactiveEscalate: cmd.String("inactive-escalate"),
+						Highlighted:      cmd.String("highlighted"),
+					})
+					if err := api.ValidateChecks(checks); err != nil {
+						return err
+					}
+
+					client, err := newAPIClient(cmd)
+					if err != nil {
+						return err
+					}
+					responses, err := client.SendChecksBatched(ctx, checks, cmd.Int("batch-size"))
+					if err != nil {
+						return err
+					}
+
+					fmt.Printf("%d checks sent successfully\n", len(checks))
+
+					// Report any non-OK statuses and errors
+					if summary := formatResponses(responses); summary != "" {
+						fmt.Println(summary)
+					}
+
+					return nil
+				},
+			},
 			{
 				Name:  "certcheck",
 				Usage: "Check SSL/TLS certificate expiry for one or more URLs",
-				Flags: []cli.Flag{
-					&cli.StringFlag{
-						Name:     "dashboard",
-						Aliases:  []string{"d"},
-						Usage:    "Dashboard name",
-						Sources:  cli.EnvVars("ALERTBINGO_DASHBOARD"),
-						Required: true,
+				Flags: append(commonFlags(commonOptions{
+					omit:     []string{"service"},
+					required: []string{"dashboard", "site", "name"},
+					usage: map[string]string{
+						"name": "Check name (e.g., ssl)",
+					},
+				}),
+					&cli.DurationFlag{
+						Name:    "timeout",
+						Usage:   "Timeout for TLS connection",
+						Sources: cli.EnvVars("ALERTBINGO_TIMEOUT"),
+						Value:   10 * time.Second,
 					},
 					&cli.StringFlag{
-						Name:     "site",
-						Aliases:  []string{"s"},
-						Usage:    "Site identifier (e.g., myapp-prod)",
-						Sources:  cli.EnvVars("ALERTBINGO_SITE"),
-						Required: true,
+						Name:    "ca-file",
+						Usage:   "PEM bundle of additional CAs to trust",
+						Sources: cli.EnvVars("ALERTBINGO_CA_FILE"),
 					},
 					&cli.StringFlag{
-						Name:     "name",
-						Aliases:  []string{"n"},
-						Usage:    "Check name (e.g., ssl)",
-						Sources:  cli.EnvVars("ALERTBINGO_NAME"),
-						Required: true,
+						Name:    "client-cert",
+						Usage:   "PEM client certificate for mutual TLS",
+						Sources: cli.EnvVars("ALERTBINGO_CLIENT_CERT"),
 					},
 					&cli.StringFlag{
-						Name:    "message",
-						Aliases: []string{"m"},
-						Usage:   "Optional long-form status message",
-						Sources: cli.EnvVars("ALERTBINGO_MESSAGE"),
+						Name:    "client-key",
+						Usage:   "PEM private key for the client certificate",
+						Sources: cli.EnvVars("ALERTBINGO_CLIENT_KEY"),
+					},
+					&cli.BoolFlag{
+						Name:    "insecure-skip-verify",
+						Usage:   "Skip certificate verification (expiry is still reported)",
+						Sources: cli.EnvVars("ALERTBINGO_INSECURE_SKIP_VERIFY"),
+					},
+					&cli.StringSliceFlag{
+						Name:  "file",
+						Usage: "Certificate file or glob to check (PEM, DER or PKCS#12); may be repeated",
 					},
 					&cli.StringFlag{
-						Name:    "inactive-expire",
-						Usage:   "Optional duration string for inactive expiry (e.g., 48h or 30m)",
-						Sources: cli.EnvVars("ALERTBINGO_INACTIVE_EXPIRE"),
+						Name:    "pkcs12-password",
+						Usage:   "Password for PKCS#12 archives",
+						Sources: cli.EnvVars("ALERTBINGO_PKCS12_PASSWORD"),
+					},
+					&cli.BoolFlag{
+						Name:    "details",
+						Usage:   "Include certificate subject, issuer, SANs, fingerprint, key and TLS details in the message",
+						Sources: cli.EnvVars("ALERTBINGO_CERT_DETAILS"),
 					},
 					&cli.StringFlag{
-						Name:    "inactive-escalate",
-						Usage:   "Optional duration string for inactive escalation (e.g., 1h or 30m)",
-						Sources: cli.EnvVars("ALERTBINGO_INACTIVE_ESCALATE"),
+						Name:  "sni",
+						Usage: "Hostname to send as SNI and verify the certificate against, instead of the URL host",
+					},
+					&cli.StringSliceFlag{
+						Name:  "connect-to",
+						Usage: "Connect to address instead of host:port, as host:port:address; repeat to check each backend",
+					},
+					&cli.StringSliceFlag{
+						Name:  "pin",
+						Usage: "Expected certificate pin as [target=]sha256/<base64 SPKI hash> or a hex SHA-256 fingerprint; may be repeated",
 					},
 					&cli.StringFlag{
-						Name:    "highlighted",
-						Usage:   "Optional highlighted status",
-						Sources: cli.EnvVars("ALERTBINGO_HIGHLIGHTED"),
+						Name:    "state-file",
+						Usage:   "File recording the last seen certificate per target, to warn when a certificate changes",
+						Sources: cli.EnvVars("ALERTBINGO_CERT_STATE_FILE"),
+					},
+					&cli.BoolFlag{
+						Name:    "ocsp",
+						Usage:   "Query the certificate's OCSP responder for revocation status",
+						Sources: cli.EnvVars("ALERTBINGO_OCSP"),
 					},
 					&cli.StringFlag{
-						Name:     "token",
-						Aliases:  []string{"t"},
-						Usage:    "API Bearer token",
-						Sources:  cli.EnvVars("ALERTBINGO_TOKEN"),
-						Required: true,
+						Name:    "ocsp-responder",
+						Usage:   "OCSP responder URL to query instead of the one in the certificate",
+						Sources: cli.EnvVars("ALERTBINGO_OCSP_RESPONDER"),
+					},
+					&cli.BoolFlag{
+						Name:  "require-ocsp-staple",
+						Usage: "Warn when an endpoint doesn't staple a valid OCSP response",
+					},
+					&cli.BoolFlag{
+						Name:  "policy",
+						Usage: "Also audit protocol versions, key sizes and signature algorithms against the TLS policy",
 					},
 					&cli.StringFlag{
-						Name:    "api-url",
-						Usage:   "API URL",
-						Sources: cli.EnvVars("ALERTBINGO_API_URL"),
-						Value:   "https://app.alert.bingo/api/v1/checks",
+						Name:  "policy-min-tls",
+						Usage: "Lowest TLS version endpoints may accept (1.0, 1.1, 1.2 or 1.3)",
+						Value: "1.2",
 					},
-					&cli.DurationFlag{
-						Name:    "timeout",
-						Usage:   "Timeout for TLS connection",
-						Sources: cli.EnvVars("ALERTBINGO_TIMEOUT"),
-						Value:   10 * time.Second,
+					&cli.IntFlag{
+						Name:  "policy-min-rsa-bits",
+						Usage: "Minimum RSA key size in bits",
+						Value: 2048,
 					},
-				},
+					&cli.IntFlag{
+						Name:  "policy-min-ecdsa-bits",
+						Usage: "Minimum ECDSA key size in bits",
+						Value: 256,
+					},
+					&cli.StringSliceFlag{
+						Name:  "policy-weak-signatures",
+						Usage: "Signature algorithms that violate the policy",
+						Value: []string{"SHA1", "MD5"},
+					},
+					&cli.StringFlag{
+						Name:  "policy-level",
+						Usage: "Alert level for policy violations: warn or alert",
+						Value: "alert",
+					},
+				),
 				Action: func(ctx context.Context, cmd *cli.Command) error {
 					urls := cmd.Args().Slice()
-					if len(urls) == 0 {
-						return fmt.Errorf("at least one URL is required")
+					files := cmd.StringSlice("file")
+					if len(urls) == 0 && len(files) == 0 {
+						return fmt.Errorf("at least one URL or --file is required")
 					}
 
-					cfg := certcheck.Config{
-						Dashboard:        cmd.String("dashboard"),
-						Site:             cmd.String("site"),
-						Name:             cmd.String("name"),
-						Message:          cmd.String("message"),
-						InactiveExpire:   cmd.String("inactive-expire"),
-						InactiveEscalate: cmd.String("inactive-escalate"),
-						Highlighted:      cmd.String("highlighted"),
-						Timeout:          cmd.Duration("timeout"),
+					message, templates, err := messageTemplates(cmd)
+					if err != nil {
+						return err
 					}
 
-					checks := certcheck.Collect(ctx, cfg, urls)
+					c := checker.CertCheckConfig{
+						URLs:              urls,
+						Files:             files,
+						Timeout:           cmd.Duration("timeout"),
+						Details:           cmd.Bool("details"),
+						PKCS12Password:    cmd.String("pkcs12-password"),
+						SNI:               cmd.String("sni"),
+						ConnectTo:         cmd.StringSlice("connect-to"),
+						Pins:              cmd.StringSlice("pin"),
+						StateFile:         cmd.String("state-file"),
+						OCSP:              cmd.Bool("ocsp"),
+						OCSPResponder:     cmd.String("ocsp-responder"),
+						RequireOCSPStaple: cmd.Bool("require-ocsp-staple"),
+						TLS:               tlsOptions(cmd),
+					}
+					if cmd.Bool("policy") {
+						c.Policy = &checker.PolicyConfig{
+							MinTLS:         cmd.String("policy-min-tls"),
+							MinRSABits:     cmd.Int("policy-min-rsa-bits"),
+							MinECDSABits:   cmd.Int("policy-min-ecdsa-bits"),
+							WeakSignatures: cmd.StringSlice("policy-weak-signatures"),
+							Level:          cmd.String("policy-level"),
+						}
+					}
+
+					check, err := checker.NewCertCheck(commonSettings(cmd, message), c)
+					if err != nil {
+						return err
+					}
+					checks, err := check.Run(ctx)
+					if err != nil {
+						return err
+					}
 
-					client := api.NewClient(cmd.String("api-url"), cmd.String("token"))
-					responses, err := client.SendChecks(ctx, checks)
+					client, err := newAPIClient(cmd)
+					if err != nil {
+						return err
+					}
+					responses, err := client.SendChecks(ctx, templates.Apply(checks))
 					if err != nil {
 						return err
 					}
@@ -333,113 +391,645 @@ func main() {
 			{
 				Name:  "urlcheck",
 				Usage: "Check URL availability, status code, and optionally body content",
-				Flags: []cli.Flag{
+				Flags: append(commonFlags(commonOptions{
+					omit:     []string{"service"},
+					required: []string{"dashboard", "site", "name"},
+					usage: map[string]string{
+						"name": "Check name (e.g., http)",
+					},
+				}),
+					&cli.DurationFlag{
+						Name:    "timeout",
+						Usage:   "Timeout for HTTP request",
+						Sources: cli.EnvVars("ALERTBINGO_TIMEOUT"),
+						Value:   10 * time.Second,
+					},
 					&cli.StringFlag{
-						Name:     "dashboard",
-						Aliases:  []string{"d"},
-						Usage:    "Dashboard name",
-						Sources:  cli.EnvVars("ALERTBINGO_DASHBOARD"),
-						Required: true,
+						Name:    "proxy",
+						Usage:   "Proxy for URL checks (http://, https://, socks5:// or socks5h://, with optional user:password@); defaults to HTTP_PROXY/HTTPS_PROXY",
+						Sources: cli.EnvVars("ALERTBINGO_PROXY"),
 					},
 					&cli.StringFlag{
-						Name:     "site",
-						Aliases:  []string{"s"},
-						Usage:    "Site identifier (e.g., myapp-prod)",
-						Sources:  cli.EnvVars("ALERTBINGO_SITE"),
-						Required: true,
+						Name:    "no-proxy",
+						Usage:   "Comma-separated hosts, domains and CIDRs to check without --proxy (e.g. .internal,10.0.0.0/8)",
+						Sources: cli.EnvVars("ALERTBINGO_NO_PROXY"),
 					},
 					&cli.StringFlag{
-						Name:     "name",
-						Aliases:  []string{"n"},
-						Usage:    "Check name (e.g., http)",
-						Sources:  cli.EnvVars("ALERTBINGO_NAME"),
-						Required: true,
+						Name:    "proxy-user",
+						Usage:   "Username for proxy authentication",
+						Sources: cli.EnvVars("ALERTBINGO_PROXY_USER"),
 					},
 					&cli.StringFlag{
-						Name:    "message",
-						Aliases: []string{"m"},
-						Usage:   "Optional long-form status message",
-						Sources: cli.EnvVars("ALERTBINGO_MESSAGE"),
+						Name:    "proxy-password",
+						Usage:   "Password for proxy authentication",
+						Sources: cli.EnvVars("ALERTBINGO_PROXY_PASSWORD"),
+					},
+					&cli.BoolFlag{
+						Name:  "ipv4",
+						Usage: "Connect over IPv4 only",
+					},
+					&cli.BoolFlag{
+						Name:  "ipv6",
+						Usage: "Connect over IPv6 only",
+					},
+					&cli.StringSliceFlag{
+						Name:  "resolve",
+						Usage: "Connect to an address instead of resolving the host, as host:port:address (e.g. example.com:443:203.0.113.10); may be repeated",
+					},
+					&cli.BoolFlag{
+						Name:  "each-address",
+						Usage: "Check each address the host resolves to separately, sending a check per address",
+					},
+					&cli.IntFlag{
+						Name:    "attempts",
+						Usage:   "Attempts before alerting; a check only alerts if every attempt fails",
+						Sources: cli.EnvVars("ALERTBINGO_ATTEMPTS"),
+						Value:   1,
+					},
+					&cli.DurationFlag{
+						Name:    "attempt-delay",
+						Usage:   "Delay between attempts",
+						Sources: cli.EnvVars("ALERTBINGO_ATTEMPT_DELAY"),
+						Value:   time.Second,
+					},
+					&cli.DurationFlag{
+						Name:    "slow-threshold",
+						Usage:   "Total response time above which a successful check warns",
+						Sources: cli.EnvVars("ALERTBINGO_SLOW_THRESHOLD"),
+						Value:   urlcheck.DefaultSlowThreshold,
+					},
+					&cli.DurationFlag{
+						Name:  "slow-dns",
+						Usage: "DNS lookup time above which a check warns (0 disables)",
+					},
+					&cli.DurationFlag{
+						Name:  "slow-connect",
+						Usage: "TCP connect time above which a check warns (0 disables)",
+					},
+					&cli.DurationFlag{
+						Name:  "slow-tls",
+						Usage: "TLS handshake time above which a check warns (0 disables)",
+					},
+					&cli.DurationFlag{
+						Name:  "slow-ttfb",
+						Usage: "Time to first byte above which a check warns (0 disables)",
 					},
 					&cli.StringFlag{
-						Name:    "inactive-expire",
-						Usage:   "Optional duration string for inactive expiry (e.g., 48h or 30m)",
-						Sources: cli.EnvVars("ALERTBINGO_INACTIVE_EXPIRE"),
+						Name:    "targets-file",
+						Aliases: []string{"f"},
+						Usage:   "File of targets to check: one \"<url> [expected_code] [expected_body]\" per line, or a YAML list (.yaml/.yml)",
+						Sources: cli.EnvVars("ALERTBINGO_TARGETS_FILE"),
+					},
+					&cli.StringSliceFlag{
+						Name:    "scenario",
+						Usage:   "YAML file defining a multi-step scenario of HTTP requests; may be repeated",
+						Sources: cli.EnvVars("ALERTBINGO_SCENARIO"),
+					},
+					&cli.IntFlag{
+						Name:    "concurrency",
+						Usage:   "Maximum number of URLs to check at once",
+						Sources: cli.EnvVars("ALERTBINGO_CONCURRENCY"),
+						Value:   10,
 					},
 					&cli.StringFlag{
-						Name:    "inactive-escalate",
-						Usage:   "Optional duration string for inactive escalation (e.g., 1h or 30m)",
-						Sources: cli.EnvVars("ALERTBINGO_INACTIVE_ESCALATE"),
+						Name:  "method",
+						Usage: "HTTP method (e.g., POST)",
+						Value: "GET",
+					},
+					&cli.StringSliceFlag{
+						Name:  "header",
+						Usage: "Request header as \"Name: value\"; may be repeated",
 					},
 					&cli.StringFlag{
-						Name:    "highlighted",
-						Usage:   "Optional highlighted status",
-						Sources: cli.EnvVars("ALERTBINGO_HIGHLIGHTED"),
+						Name:  "body",
+						Usage: "Request body",
 					},
 					&cli.StringFlag{
-						Name:     "token",
-						Aliases:  []string{"t"},
-						Usage:    "API Bearer token",
-						Sources:  cli.EnvVars("ALERTBINGO_TOKEN"),
-						Required: true,
+						Name:  "body-file",
+						Usage: "File to read the request body from",
+					},
+					&cli.StringFlag{
+						Name:    "basic-auth-user",
+						Usage:   "Username for HTTP basic authentication",
+						Sources: cli.EnvVars("ALERTBINGO_BASIC_AUTH_USER"),
+					},
+					&cli.StringFlag{
+						Name:    "basic-auth-password",
+						Usage:   "Password for HTTP basic authentication",
+						Sources: cli.EnvVars("ALERTBINGO_BASIC_AUTH_PASSWORD"),
+					},
+					&cli.StringFlag{
+						Name:    "bearer-token",
+						Usage:   "Bearer token sent in the Authorization header",
+						Sources: cli.EnvVars("ALERTBINGO_BEARER_TOKEN"),
+					},
+					&cli.StringFlag{
+						Name:  "host-header",
+						Usage: "Host header to send instead of the URL host",
+					},
+					&cli.StringFlag{
+						Name:  "user-agent",
+						Usage: "User-Agent header to send",
+					},
+					&cli.BoolFlag{
+						Name:  "follow-redirects",
+						Usage: "Follow redirects and check the final response",
+					},
+					&cli.IntFlag{
+						Name:  "max-redirects",
+						Usage: "Maximum number of redirects to follow",
+						Value: urlcheck.DefaultMaxRedirects,
+					},
+					&cli.StringFlag{
+						Name:  "expect-final-url",
+						Usage: "Prefix the URL after redirects must start with (e.g. https://www.example.com/)",
+					},
+					&cli.StringSliceFlag{
+						Name:  "allow-redirect-host",
+						Usage: "Host redirects may go to besides the original host (e.g. *.example.com); may be repeated",
+					},
+					&cli.Int64Flag{
+						Name:  "body-limit",
+						Usage: "Maximum number of response body bytes to read",
+						Value: urlcheck.DefaultBodyLimit,
+					},
+					&cli.Int64Flag{
+						Name:  "min-body-bytes",
+						Usage: "Minimum response body size in bytes",
+					},
+					&cli.Int64Flag{
+						Name:  "max-body-bytes",
+						Usage: "Maximum response body size in bytes",
+					},
+					&cli.StringFlag{
+						Name:  "expect-sha256",
+						Usage: "Hex SHA-256 the response body must have",
+					},
+					&cli.StringFlag{
+						Name:    "baseline-file",
+						Usage:   "File recording each URL's last content hash; a change raises a warning",
+						Sources: cli.EnvVars("ALERTBINGO_BASELINE_FILE"),
+					},
+					&cli.StringFlag{
+						Name:  "body-regex",
+						Usage: "Regular expression the response body must match",
+					},
+					&cli.StringSliceFlag{
+						Name:  "body-not-contains",
+						Usage: "String the response body must not contain (e.g. \"Maintenance mode\"); may be repeated",
+					},
+					&cli.StringSliceFlag{
+						Name:  "expect-header",
+						Usage: "Response header assertion, e.g. \"Content-Type =~ ^application/json\" or \"Strict-Transport-Security exists\"; may be repeated",
+					},
+					&cli.StringSliceFlag{
+						Name:  "json",
+						Usage: "JSON body assertion, e.g. \"db == ok\" or \"queue_depth < 1000\" (ops: == != =~ < <= > >= exists !exists); may be repeated",
+					},
+					&cli.StringFlag{
+						Name:  "json-value",
+						Usage: "JSON path whose value is reported as the check value (e.g. queue_depth)",
+					},
+					&cli.StringFlag{
+						Name:    "ca-file",
+						Usage:   "PEM bundle of additional CAs to trust",
+						Sources: cli.EnvVars("ALERTBINGO_CA_FILE"),
 					},
 					&cli.StringFlag{
-						Name:    "api-url",
-						Usage:   "API URL",
-						Sources: cli.EnvVars("ALERTBINGO_API_URL"),
-						Value:   "https://app.alert.bingo/api/v1/checks",
+						Name:    "client-cert",
+						Usage:   "PEM client certificate for mutual TLS",
+						Sources: cli.EnvVars("ALERTBINGO_CLIENT_CERT"),
+					},
+					&cli.StringFlag{
+						Name:    "client-key",
+						Usage:   "PEM private key for the client certificate",
+						Sources: cli.EnvVars("ALERTBINGO_CLIENT_KEY"),
+					},
+					&cli.BoolFlag{
+						Name:    "insecure-skip-verify",
+						Usage:   "Skip certificate verification",
+						Sources: cli.EnvVars("ALERTBINGO_INSECURE_SKIP_VERIFY"),
+					},
+				),
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					targets, err := urlcheck.ParseTargetArgs(cmd.Args().Slice())
+					if err != nil {
+						return err
+					}
+
+					if path := cmd.String("targets-file"); path != "" {
+						fileTargets, err := urlcheck.LoadTargets(path)
+						if err != nil {
+							return err
+						}
+						targets = append(targets, fileTargets...)
+					}
+
+					if len(targets) == 0 && len(cmd.StringSlice("scenario")) == 0 {
+						return fmt.Errorf("URL or --scenario is required")
+					}
+
+					defaults := urlcheck.CheckParams{
+						Method:               cmd.String("method"),
+						Body:                 cmd.String("body"),
+						BodyFile:             cmd.String("body-file"),
+						BasicAuthUser:        cmd.String("basic-auth-user"),
+						BasicAuthPassword:    cmd.String("basic-auth-password"),
+						BearerToken:          cmd.String("bearer-token"),
+						Host:                 cmd.String("host-header"),
+						UserAgent:            cmd.String("user-agent"),
+						FollowRedirects:      cmd.Bool("follow-redirects"),
+						MaxRedirects:         cmd.Int("max-redirects"),
+						ExpectedFinalURL:     cmd.String("expect-final-url"),
+						AllowedRedirectHosts: cmd.StringSlice("allow-redirect-host"),
+						MinBodyBytes:         cmd.Int64("min-body-bytes"),
+						MaxBodyBytes:         cmd.Int64("max-body-bytes"),
+						ExpectedSHA256:       cmd.String("expect-sha256"),
+						BodyRegex:            cmd.String("body-regex"),
+						BodyNotContains:      cmd.StringSlice("body-not-contains"),
+						HeaderAssertions:     cmd.StringSlice("expect-header"),
+						JSONAssertions:       cmd.StringSlice("json"),
+						JSONValuePath:        cmd.String("json-value"),
+					}
+					for _, spec := range cmd.StringSlice("header") {
+						name, value, err := urlcheck.ParseHeader(spec)
+						if err != nil {
+							return err
+						}
+						if defaults.Headers == nil {
+							defaults.Headers = make(map[string]string)
+						}
+						defaults.Headers[name] = value
+					}
+					targets = urlcheck.ApplyDefaults(targets, defaults)
+
+					message, templates, err := messageTemplates(cmd)
+					if err != nil {
+						return err
+					}
+
+					check, err := checker.NewURLCheck(commonSettings(cmd, message), checker.URLCheckConfig{
+						Targets:       targets,
+						Scenarios:     cmd.StringSlice("scenario"),
+						Timeout:       cmd.Duration("timeout"),
+						Concurrency:   cmd.Int("concurrency"),
+						Attempts:      cmd.Int("attempts"),
+						AttemptDelay:  cmd.Duration("attempt-delay"),
+						SlowThreshold: cmd.Duration("slow-threshold"),
+						SlowDNS:       cmd.Duration("slow-dns"),
+						SlowConnect:   cmd.Duration("slow-connect"),
+						SlowTLS:       cmd.Duration("slow-tls"),
+						SlowTTFB:      cmd.Duration("slow-ttfb"),
+						BodyLimit:     cmd.Int64("body-limit"),
+						BaselineFile:  cmd.String("baseline-file"),
+						IPv4:          cmd.Bool("ipv4"),
+						IPv6:          cmd.Bool("ipv6"),
+						Resolve:       cmd.StringSlice("resolve"),
+						EachAddress:   cmd.Bool("each-address"),
+						Proxy: proxyconfig.Options{
+							URL:      cmd.String("proxy"),
+							NoProxy:  cmd.String("no-proxy"),
+							Username: cmd.String("proxy-user"),
+							Password: cmd.String("proxy-password"),
+						},
+						TLS: tlsOptions(cmd),
+					})
+					if err != nil {
+						return err
+					}
+					checks, err := check.Run(ctx)
+					if err != nil {
+						return err
+					}
+
+					client, err := newAPIClient(cmd)
+					if err != nil {
+						return err
+					}
+					responses, err := client.SendChecks(ctx, templates.Apply(checks))
+					if err != nil {
+						return err
+					}
+
+					fmt.Println("URL check sent successfully")
+
+					// Report any non-OK statuses and errors
+					if summary := formatResponses(responses); summary != "" {
+						fmt.Println(summary)
+					}
+
+					return nil
+				},
+			},
+			{
+				Name:      "exec",
+				Usage:     "Run a command, such as a cron job, and send its outcome as a check",
+				ArgsUsage: "-- command [args...]",
+				Flags: append(commonFlags(commonOptions{
+					required: []string{"dashboard", "site", "name"},
+					usage: map[string]string{
+						"name": "Check name (e.g., nightly-backup)",
 					},
+				}),
 					&cli.DurationFlag{
 						Name:    "timeout",
-						Usage:   "Timeout for HTTP request",
+						Usage:   "Kill the command and its children and alert after this long (e.g. 2h); 0 for no timeout",
 						Sources: cli.EnvVars("ALERTBINGO_TIMEOUT"),
-						Value:   10 * time.Second,
 					},
-				},
+					&cli.StringSliceFlag{
+						Name:  "exit-level",
+						Usage: "Alert level for exit codes as codes=level (e.g. 1=warn or 2-5,9=ok); unmapped non-zero codes alert; may be repeated",
+					},
+					&cli.IntFlag{
+						Name:  "stderr-lines",
+						Usage: "Number of stderr lines to include in the message of a failed run; 0 for none",
+						Value: execcheck.DefaultTailLines,
+					},
+				),
 				Action: func(ctx context.Context, cmd *cli.Command) error {
 					args := cmd.Args().Slice()
 					if len(args) == 0 {
-						return fmt.Errorf("URL is required")
+						return fmt.Errorf("command is required")
+					}
+
+					message, templates, err := messageTemplates(cmd)
+					if err != nil {
+						return err
 					}
 
-					params := urlcheck.CheckParams{
-						URL: args[0],
+					stderrLines := cmd.Int("stderr-lines")
+					job, err := checker.NewExec(commonSettings(cmd, message), checker.ExecConfig{
+						Command:     args,
+						Timeout:     cmd.Duration("timeout"),
+						ExitLevels:  cmd.StringSlice("exit-level"),
+						StderrLines: &stderrLines,
+					})
+					if err != nil {
+						return err
 					}
 
-					// Parse optional expected code
-					if len(args) >= 2 {
-						code, err := strconv.Atoi(args[1])
-						if err != nil {
-							return fmt.Errorf("invalid expected status code: %s", args[1])
+					client, err := newAPIClient(cmd)
+					if err != nil {
+						return err
+					}
+
+					result, check := job.RunCommand(ctx)
+
+					// Send the result even if we were interrupted, which is when it matters most
+					responses, err := client.SendChecks(context.WithoutCancel(ctx), templates.Apply([]api.CheckPayload{check}))
+					if err != nil {
+						return err
+					}
+
+					fmt.Println("Exec check sent successfully")
+
+					// Report any non-OK statuses and errors
+					if summary := formatResponses(responses); summary != "" {
+						fmt.Println(summary)
+					}
+
+					// Exit as the command did, so cron and callers still see the failure
+					switch {
+					case result.TimedOut:
+						return cli.Exit("", 124)
+					case result.ExitCode < 0:
+						return cli.Exit("", 1)
+					case result.ExitCode > 0:
+						return cli.Exit("", result.ExitCode)
+					}
+
+					return nil
+				},
+			},
+			{
+				Name:      "nagios",
+				Usage:     "Run Nagios-compatible monitoring plugins and send their results as checks",
+				ArgsUsage: "[-- plugin [args...]]",
+				Flags: append(commonFlags(commonOptions{
+					required: []string{"dashboard", "site"},
+					usage: map[string]string{
+						"name": "Check name for the plugin given as arguments (default: the plugin file name, e.g. check_mysql)",
+					},
+				}),
+					&cli.DurationFlag{
+						Name:    "timeout",
+						Usage:   "Time a plugin may run before it's killed and alerts",
+						Sources: cli.EnvVars("ALERTBINGO_TIMEOUT"),
+						Value:   nagios.DefaultTimeout,
+					},
+					&cli.StringFlag{
+						Name:  "value-label",
+						Usage: "Performance data label to report as the value (default: the first)",
+					},
+					&cli.StringFlag{
+						Name:    "plugins-file",
+						Usage:   "YAML file listing plugin invocations to run",
+						Sources: cli.EnvVars("ALERTBINGO_PLUGINS_FILE"),
+					},
+					&cli.IntFlag{
+						Name:    "concurrency",
+						Usage:   "Maximum number of plugins to run at once",
+						Sources: cli.EnvVars("ALERTBINGO_CONCURRENCY"),
+						Value:   10,
+					},
+				),
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					args := cmd.Args().Slice()
+					if len(args) == 0 && cmd.String("plugins-file") == "" {
+						return fmt.Errorf("plugin command or --plugins-file is required")
+					}
+
+					message, templates, err := messageTemplates(cmd)
+					if err != nil {
+						return err
+					}
+
+					check, err := checker.NewNagios(commonSettings(cmd, message), checker.NagiosConfig{
+						Command:     args,
+						ValueLabel:  cmd.String("value-label"),
+						PluginsFile: cmd.String("plugins-file"),
+						Timeout:     cmd.Duration("timeout"),
+						Concurrency: cmd.Int("concurrency"),
+					})
+					if err != nil {
+						return err
+					}
+					checks, err := check.Run(ctx)
+					if err != nil {
+						return err
+					}
+
+					client, err := newAPIClient(cmd)
+					if err != nil {
+						return err
+					}
+					responses, err := client.SendChecks(ctx, templates.Apply(checks))
+					if err != nil {
+						return err
+					}
+
+					fmt.Println("Nagios checks sent successfully")
+
+					// Report any non-OK statuses and errors
+					if summary := formatResponses(responses); summary != "" {
+						fmt.Println(summary)
+					}
+
+					return nil
+				},
+			},
+			{
+				Name:      "plugin",
+				Usage:     "Run external check plugins that report checks as JSON",
+				ArgsUsage: "[plugin...]",
+				Flags: append(commonFlags(commonOptions{
+					defaults: true,
+					omit:     []string{"name"},
+					required: []string{"dashboard", "site"},
+					usage: map[string]string{
+						"value": "Optional template for the value instead of the plugin's (e.g. \"{{.Value}} jobs\")",
+					},
+				}),
+					&cli.StringFlag{
+						Name:    "plugin-dir",
+						Usage:   "Directory plugins are looked up in; every executable in it runs when no plugins are given",
+						Sources: cli.EnvVars("ALERTBINGO_PLUGIN_DIR"),
+						Value:   plugincheck.DefaultDir,
+					},
+					&cli.StringFlag{
+						Name:    "plugin-config",
+						Usage:   "JSON object passed to the plugins given as arguments or discovered (e.g. '{\"queue\": \"emails\"}')",
+						Sources: cli.EnvVars("ALERTBINGO_PLUGIN_CONFIG"),
+					},
+					&cli.StringFlag{
+						Name:    "plugins-file",
+						Usage:   "YAML file listing plugin invocations to run",
+						Sources: cli.EnvVars("ALERTBINGO_PLUGINS_FILE"),
+					},
+					&cli.DurationFlag{
+						Name:    "timeout",
+						Usage:   "Time a plugin may run before it's killed and alerts",
+						Sources: cli.EnvVars("ALERTBINGO_TIMEOUT"),
+						Value:   plugincheck.DefaultTimeout,
+					},
+					&cli.IntFlag{
+						Name:    "concurrency",
+						Usage:   "Maximum number of plugins to run at once",
+						Sources: cli.EnvVars("ALERTBINGO_CONCURRENCY"),
+						Value:   10,
+					},
+				),
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					var config map[string]any
+					if raw := cmd.String("plugin-config"); raw != "" {
+						if err := json.Unmarshal([]byte(raw), &config); err != nil {
+							return fmt.Errorf("invalid --plugin-config: %w", err)
 						}
-						params.ExpectedCode = code
 					}
 
-					// Parse optional expected body string
-					if len(args) >= 3 {
-						params.ExpectedBody = args[2]
+					var plugins []plugincheck.Plugin
+					for _, name := range cmd.Args().Slice() {
+						plugins = append(plugins, plugincheck.Plugin{Path: name, Config: config})
 					}
 
-					cfg := urlcheck.Config{
-						Dashboard:        cmd.String("dashboard"),
-						Site:             cmd.String("site"),
-						Name:             cmd.String("name"),
-						Message:          cmd.String("message"),
-						InactiveExpire:   cmd.String("inactive-expire"),
-						InactiveEscalate: cmd.String("inactive-escalate"),
-						Highlighted:      cmd.String("highlighted"),
-						Timeout:          cmd.Duration("timeout"),
+					message, templates, err := messageTemplates(cmd)
+					if err != nil {
+						return err
 					}
 
-					check := urlcheck.Check(ctx, cfg, params)
+					check, err := checker.NewPlugin(commonSettings(cmd, message), checker.PluginConfig{
+						Config:      config,
+						Plugins:     plugins,
+						PluginsFile: cmd.String("plugins-file"),
+						Dir:         cmd.String("plugin-dir"),
+						Timeout:     cmd.Duration("timeout"),
+						Concurrency: cmd.Int("concurrency"),
+					})
+					if err != nil {
+						return err
+					}
+					checks, err := check.Run(ctx)
+					if err != nil {
+						return err
+					}
 
-					client := api.NewClient(cmd.String("api-url"), cmd.String("token"))
-					responses, err := client.SendChecks(ctx, []api.CheckPayload{check})
+					client, err := newAPIClient(cmd)
 					if err != nil {
 						return err
 					}
 
-					fmt.Println("URL check sent successfully")
+					responses, err := client.SendChecksBatched(ctx, templates.Apply(checks), api.DefaultBatchSize)
+					if err != nil {
+						return err
+					}
+
+					fmt.Printf("%d checks sent successfully\n", len(checks))
+
+					// Report any non-OK statuses and errors
+					if summary := formatResponses(responses); summary != "" {
+						fmt.Println(summary)
+					}
+
+					return nil
+				},
+			},
+			{
+				Name:  "run",
+				Usage: "Run the checks in a manifest file",
+				Flags: append(commonFlags(commonOptions{
+					defaults: true,
+					omit:     []string{"service", "name", "message", "value", "inactive-expire", "inactive-escalate", "highlighted"},
+				}),
+					&cli.StringFlag{
+						Name:     "config",
+						Aliases:  []string{"c"},
+						Usage:    "Path to the YAML check manifest (check types: " + strings.Join(checker.Types(), ", ") + ")",
+						Sources:  cli.EnvVars("ALERTBINGO_CONFIG"),
+						Required: true,
+					},
+					&cli.IntFlag{
+						Name:    "concurrency",
+						Usage:   "Number of checks to run at once",
+						Sources: cli.EnvVars("ALERTBINGO_CONCURRENCY"),
+						Value:   4,
+					},
+					&cli.IntFlag{
+						Name:    "batch-size",
+						Usage:   "Maximum number of checks per API request",
+						Sources: cli.EnvVars("ALERTBINGO_BATCH_SIZE"),
+						Value:   api.DefaultBatchSize,
+					},
+				),
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					manifest, err := checker.LoadManifest(cmd.String("config"))
+					if err != nil {
+						return err
+					}
+
+					runner, err := checker.NewRunner(manifest, checker.Common{
+						Dashboard: cmd.String("dashboard"),
+						Site:      cmd.String("site"),
+					})
+					if err != nil {
+						return err
+					}
+
+					client, err := newAPIClient(cmd)
+					if err != nil {
+						return err
+					}
+
+					checks := runner.Run(ctx, cmd.Int("concurrency"))
+					if err := api.ValidateChecks(checks); err != nil {
+						return err
+					}
+
+					responses, err := client.SendChecksBatched(ctx, checks, cmd.Int("batch-size"))
+					if err != nil {
+						return err
+					}
+
+					fmt.Printf("%d checks sent successfully\n", len(checks))
 
 					// Report any non-OK statuses and errors
 					if summary := formatResponses(responses); summary != "" {
@@ -452,11 +1042,173 @@ func main() {
 		},
 	}
 
-	if err := cmd.Run(context.Background(), os.Args); err != nil {
+	// Cancel on SIGINT and SIGTERM so running checks, and the process group of an
+	// exec command, are stopped and their results still sent
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := cmd.Run(ctx, os.Args); err != nil {
 		log.Fatal(err)
 	}
 }
 
+// commonSettings returns the common check settings from the command's flags,
+// with the message from messageTemplates
+func commonSettings(cmd *cli.Command, message string) checker.Common {
+	return checker.Common{
+		Dashboard:        cmd.String("dashboard"),
+		Site:             cmd.String("site"),
+		Service:          cmd.String("service"),
+		Name:             cmd.String("name"),
+		Message:          message,
+		InactiveExpire:   cmd.String("inactive-expire"),
+		InactiveEscalate: cmd.String("inactive-escalate"),
+		Highlighted:      cmd.String("highlighted"),
+	}
+}
+
+// messageTemplates parses the --message and --value flags as templates. A templated
+// message is rendered after the checks run, so the message returned for the check
+// config is empty in that case.
+func messageTemplates(cmd *cli.Command) (string, *checktemplate.Templates, error) {
+	message := cmd.String("message")
+	templates, err := checktemplate.Parse(message, cmd.String("value"))
+	if err != nil {
+		return "", nil, err
+	}
+	if checktemplate.IsTemplate(message) {
+		message = ""
+	}
+	return message, templates, nil
+}
+
+// commonOptions tailors the flags from commonFlags to a command
+type commonOptions struct {
+	defaults bool              // the check settings apply to checks that don't set their own
+	omit     []string          // common flags the command doesn't take
+	required []string          // common flags the command requires; --token always is
+	usage    map[string]string // usage replacing the default, e.g. to give an example --name
+}
+
+// commonFlags returns the check settings and API flags shared by the commands.
+// Each command appends its own flags.
+func commonFlags(opts commonOptions) []cli.Flag {
+	forChecks := ""
+	if opts.defaults {
+		forChecks = " for checks that don't set one"
+	}
+
+	flags := []*cli.StringFlag{
+		{
+			Name:    "dashboard",
+			Aliases: []string{"d"},
+			Usage:   "Dashboard name" + forChecks,
+			Sources: cli.EnvVars("ALERTBINGO_DASHBOARD"),
+		},
+		{
+			Name:    "site",
+			Aliases: []string{"s"},
+			Usage:   "Site identifier" + forChecks + " (e.g., myapp-prod)",
+			Sources: cli.EnvVars("ALERTBINGO_SITE"),
+		},
+		{
+			Name:    "service",
+			Usage:   "Service name" + forChecks + " (default: the hostname)",
+			Sources: cli.EnvVars("ALERTBINGO_SERVICE"),
+		},
+		{
+			Name:    "name",
+			Aliases: []string{"n"},
+			Usage:   "Check name",
+			Sources: cli.EnvVars("ALERTBINGO_NAME"),
+		},
+		{
+			Name:    "message",
+			Aliases: []string{"m"},
+			Usage:   "Optional long-form status message; may be a template (e.g. \"{{.Host}}: see https://wiki/{{.Name}}\")",
+			Sources: cli.EnvVars("ALERTBINGO_MESSAGE"),
+		},
+		{
+			Name:  "value",
+			Usage: "Optional template for the value instead of the measured one (e.g. \"{{printf \\\"%.1f\\\" .Raw}}%\")",
+		},
+		{
+			Name:    "inactive-expire",
+			Usage:   "Optional duration string for inactive expiry" + forChecks + " (e.g., 48h or 30m)",
+			Sources: cli.EnvVars("ALERTBINGO_INACTIVE_EXPIRE"),
+		},
+		{
+			Name:    "inactive-escalate",
+			Usage:   "Optional duration string for inactive escalation" + forChecks + " (e.g., 1h or 30m)",
+			Sources: cli.EnvVars("ALERTBINGO_INACTIVE_ESCALATE"),
+		},
+		{
+			Name:    "highlighted",
+			Usage:   "Optional highlighted status" + forChecks,
+			Sources: cli.EnvVars("ALERTBINGO_HIGHLIGHTED"),
+		},
+		{
+			Name:     "token",
+			Aliases:  []string{"t"},
+			Usage:    "API Bearer token",
+			Sources:  cli.EnvVars("ALERTBINGO_TOKEN"),
+			Required: true,
+		},
+		{
+			Name:    "api-url",
+			Usage:   "API URL",
+			Sources: cli.EnvVars("ALERTBINGO_API_URL"),
+			Value:   "https://app.alert.bingo/api/v1/checks",
+		},
+		{
+			Name:    "api-proxy",
+			Usage:   "Proxy for API requests (http://, https://, socks5:// or socks5h://, with optional user:password@); defaults to HTTPS_PROXY",
+			Sources: cli.EnvVars("ALERTBINGO_API_PROXY"),
+		},
+	}
+
+	var selected []cli.Flag
+	for _, flag := range flags {
+		if slices.Contains(opts.omit, flag.Name) {
+			continue
+		}
+		if usage, ok := opts.usage[flag.Name]; ok {
+			flag.Usage = usage
+		}
+		if slices.Contains(opts.required, flag.Name) {
+			flag.Required = true
+		}
+		selected = append(selected, flag)
+	}
+	return selected
+}
+
+// newAPIClient creates the API client from the --api-url, --token and --api-proxy flags
+func newAPIClient(cmd *cli.Command) (*api.Client, error) {
+	client := api.NewClient(cmd.String("api-url"), cmd.String("token"))
+
+	proxy, err := proxyconfig.Options{URL: cmd.String("api-proxy")}.Func()
+	if err != nil {
+		return nil, err
+	}
+	if proxy != nil {
+		client.UseProxy(proxy)
+	}
+
+	return client, nil
+}
+
+// tlsOptions returns the TLS client options from the --ca-file, --client-cert,
+// --client-key and --insecure-skip-verify flags
+func tlsOptions(cmd *cli.Command) tlsconfig.Options {
+	return tlsconfig.Options{
+		CAFile:             cmd.String("ca-file"),
+		ClientCert:         cmd.String("client-cert"),
+		ClientKey:          cmd.String("client-key"),
+		InsecureSkipVerify: cmd.Bool("insecure-skip-verify"),
+	}
+}
+
 // formatResponses returns a formatted summary of non-OK statuses and errors
 func formatResponses(responses []api.Response) string {
 	// Collect unique non-OK statuses
@@ -0,0 +1,273 @@
+// Package execcheck runs a command, such as a cron job, and reports its outcome as a check.
+package execcheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alertbingo/alertbingo/api"
+	"github.com/alertbingo/alertbingo/internal/checkutil"
+)
+
+// DefaultTailLines is the number of stderr lines included in the message of a failed run
+const DefaultTailLines = 10
+
+// maxTailBytes bounds the stderr kept for the message, however long its lines
+const maxTailBytes = 4096
+
+// waitDelay is how long to wait for the output to close after the command exits
+// or is killed, e.g. when a background process it started still holds stdout
+const waitDelay = 5 * time.Second
+
+// Config holds the configuration for a command check
+type Config struct {
+	Dashboard        string
+	Site             string
+	Service          string
+	Name             string
+	Message          string
+	InactiveExpire   string
+	InactiveEscalate string
+	Highlighted      string
+	Timeout          time.Duration // kill the command's process group after this long; 0 for no timeout
+	ExitLevels       []ExitLevel   // alert levels for exit codes; unmatched non-zero codes alert
+	TailLines        *int          // stderr lines to include in the message of a failed run; nil for DefaultTailLines
+	Stdout           io.Writer     // receives the command's stdout; defaults to os.Stdout
+	Stderr           io.Writer     // receives the command's stderr; defaults to os.Stderr
+}
+
+// ExitLevel maps a range of exit codes to an alert level
+type ExitLevel struct {
+	Min, Max   int
+	AlertLevel int
+}
+
+// ParseExitLevel parses an exit code mapping of the form codes=level, where
+// codes is a comma-separated list of codes and ranges, e.g. "1=warn" or "2-5,9=alert"
+func ParseExitLevel(spec string) ([]ExitLevel, error) {
+	codes, level, ok := strings.Cut(spec, "=")
+	if !ok {
+		return nil, fmt.Errorf("invalid exit level: %s (must be codes=level)", spec)
+	}
+
+	alertLevel, err := api.ParseAlertLevel(strings.TrimSpace(level))
+	if err != nil {
+		return nil, fmt.Errorf("invalid exit level: %s: %w", spec, err)
+	}
+
+	var levels []ExitLevel
+	for _, part := range strings.Split(codes, ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		min, err := parseExitCode(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exit code %q in %s", part, spec)
+		}
+		max := min
+		if isRange {
+			if max, err = parseExitCode(to); err != nil || max < min {
+				return nil, fmt.Errorf("invalid exit code range %q in %s", part, spec)
+			}
+		}
+		levels = append(levels, ExitLevel{Min: min, Max: max, AlertLevel: alertLevel})
+	}
+
+	return levels, nil
+}
+
+func parseExitCode(s string) (int, error) {
+	code, err := strconv.Atoi(s)
+	if err != nil || code < 0 || code > 255 {
+		return 0, fmt.Errorf("invalid exit code: %s", s)
+	}
+	return code, nil
+}
+
+// alertLevel returns the alert level for an exit code. The first matching
+// mapping wins; otherwise 0 is ok and anything else alerts.
+func alertLevel(levels []ExitLevel, code int) int {
+	for _, l := range levels {
+		if code >= l.Min && code <= l.Max {
+			return l.AlertLevel
+		}
+	}
+	if code == 0 {
+		return 0
+	}
+	return 2
+}
+
+// Result holds the outcome of running a command
+type Result struct {
+	ExitCode   int           // the command's exit code, or -1 if it was killed or didn't start
+	Duration   time.Duration // how long the command ran
+	TimedOut   bool          // the command was killed after the timeout
+	Err        error         // why the command didn't start or exit normally
+	StderrTail string        // the last lines of stderr
+}
+
+// Run runs the command in args, streaming its output, and returns the result
+// and the check payload reporting it
+func Run(ctx context.Context, cfg Config, args []string) (Result, api.CheckPayload) {
+	result := run(ctx, cfg, args)
+	return result, buildPayload(cfg, args, result)
+}
+
+func run(ctx context.Context, cfg Config, args []string) Result {
+	if len(args) == 0 {
+		return Result{ExitCode: -1, Err: errors.New("no command given")}
+	}
+
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+
+	stdout, stderr := cfg.Stdout, cfg.Stderr
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	tailLines := DefaultTailLines
+	if cfg.TailLines != nil {
+		tailLines = *cfg.TailLines
+	}
+	tail := &tailWriter{max: maxTailBytes}
+
+	cmd := Command(ctx, args)
+	cmd.Stdin = stdin()
+	cmd.Stdout = stdout
+	cmd.Stderr = io.MultiWriter(stderr, tail)
+
+	start := time.Now()
+	err := cmd.Run()
+	result := Result{
+		ExitCode:   -1,
+		Duration:   time.Since(start),
+		StderrTail: tail.lastLines(tailLines),
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.TimedOut = true
+		result.Err = fmt.Errorf("timed out after %s", cfg.Timeout)
+	case ctx.Err() == context.Canceled:
+		result.Err = errors.New("interrupted")
+	case err == nil:
+		result.ExitCode = 0
+	case errors.As(err, &exitErr) && exitErr.Exited():
+		result.ExitCode = exitErr.ExitCode()
+	case errors.As(err, &exitErr):
+		result.Err = fmt.Errorf("killed: %s", exitErr.ProcessState)
+	case errors.Is(err, exec.ErrWaitDelay):
+		// The command exited, but something it started kept the output open
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	default:
+		result.Err = fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+
+	return result
+}
+
+// stdin returns os.Stdin unless it's a terminal or other character device. The
+// command runs in a background process group, so reading a terminal would stop
+// it with SIGTTIN until the timeout.
+func stdin() io.Reader {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice != 0 {
+		return nil
+	}
+	return os.Stdin
+}
+
+// Command returns a command for args that runs in its own process group. When
+// ctx is done, the command is killed along with any processes it started.
+func Command(ctx context.Context, args []string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.WaitDelay = waitDelay
+	killProcessGroup(cmd)
+	return cmd
+}
+
+func buildPayload(cfg Config, args []string, result Result) api.CheckPayload {
+	level := 2
+	var reason string
+	if result.Err != nil {
+		reason = result.Err.Error()
+	} else {
+		level = alertLevel(cfg.ExitLevels, result.ExitCode)
+		reason = fmt.Sprintf("exit code %d", result.ExitCode)
+	}
+
+	if len(args) > 0 {
+		reason = fmt.Sprintf("%s: %s", strings.Join(args, " "), reason)
+	}
+	if level > 0 && result.StderrTail != "" {
+		reason += "; stderr: " + result.StderrTail
+	}
+
+	return api.CheckPayload{
+		Dashboard:        cfg.Dashboard,
+		Site:             cfg.Site,
+		Service:          cfg.Service,
+		Name:             cfg.Name,
+		AlertLevel:       level,
+		Value:            result.Duration.Round(time.Millisecond).String(),
+		Message:          checkutil.AppendReason(cfg.Message, reason),
+		InactiveExpire:   cfg.InactiveExpire,
+		InactiveEscalate: cfg.InactiveEscalate,
+		Highlighted:      cfg.Highlighted,
+		Measurement: &api.Measurement{
+			Raw:        result.Duration,
+			Values:     map[string]any{"exit_code": result.ExitCode, "stderr": result.StderrTail},
+			Thresholds: map[string]any{"timeout": cfg.Timeout},
+			Target:     strings.Join(args, " "),
+		},
+	}
+}
+
+// tailWriter keeps the last max bytes written to it
+type tailWriter struct {
+	max int
+	buf []byte
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if len(p) > w.max {
+		p = p[len(p)-w.max:]
+	}
+	w.buf = append(w.buf, p...)
+	if over := len(w.buf) - w.max; over > 0 {
+		w.buf = append(w.buf[:0], w.buf[over:]...)
+	}
+	return n, nil
+}
+
+// lastLines returns up to n of the last non-empty lines written, joined with " | "
+func (w *tailWriter) lastLines(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	var lines []string
+	for _, line := range bytes.Split(w.buf, []byte("\n")) {
+		if line := strings.TrimSpace(string(line)); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, " | ")
+}
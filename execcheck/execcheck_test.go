@@ -0,0 +1,143 @@
+package execcheck
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseExitLevel(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []ExitLevel
+		wantErr bool
+	}{
+		{"1=warn", []ExitLevel{{1, 1, 1}}, false},
+		{"2-5,9=ok", []ExitLevel{{2, 5, 0}, {9, 9, 0}}, false},
+		{"3=critical", nil, true},
+		{"5-2=warn", nil, true},
+		{"256=warn", nil, true},
+		{"warn", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseExitLevel(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseExitLevel(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseExitLevel(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	levels, _ := ParseExitLevel("3=warn")
+
+	tests := []struct {
+		name        string
+		script      string
+		wantLevel   int
+		wantCode    int
+		wantMessage string
+	}{
+		{"success", "echo done; echo noise >&2", 0, 0, "Backup - sh -c echo done; echo noise >&2: exit code 0"},
+		{"failure", "echo one >&2; echo two >&2; exit 1", 2, 1, "exit code 1; stderr: one | two"},
+		{"mapped exit code", "exit 3", 1, 3, "exit code 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			cfg := Config{Dashboard: "test", Site: "test-site", Name: "backup", Message: "Backup", ExitLevels: levels, Stdout: &stdout, Stderr: &stderr}
+
+			result, check := Run(context.Background(), cfg, []string{"sh", "-c", tt.script})
+			if result.ExitCode != tt.wantCode || check.AlertLevel != tt.wantLevel {
+				t.Errorf("expected exit code %d level %d, got %d level %d (%s)", tt.wantCode, tt.wantLevel, result.ExitCode, check.AlertLevel, check.Message)
+			}
+			if !strings.HasSuffix(check.Message, tt.wantMessage) {
+				t.Errorf("expected message ending %q, got %q", tt.wantMessage, check.Message)
+			}
+			if tt.name == "success" && (stdout.String() != "done\n" || stderr.String() != "noise\n") {
+				t.Errorf("expected output to be streamed, got stdout %q stderr %q", stdout.String(), stderr.String())
+			}
+		})
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	cfg := Config{Name: "slow", Timeout: 100 * time.Millisecond, Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}}
+
+	// The background sleep keeps stdout open, so this only returns promptly if
+	// the whole process group is killed
+	start := time.Now()
+	result, check := Run(context.Background(), cfg, []string{"sh", "-c", "sleep 10 & sleep 10"})
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("expected the process group to be killed, took %s", elapsed)
+	}
+	if !result.TimedOut || check.AlertLevel != 2 || !strings.Contains(check.Message, "timed out after 100ms") {
+		t.Errorf("expected timeout alert, got level %d (%s)", check.AlertLevel, check.Message)
+	}
+}
+
+func TestRun_Interrupted(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	cfg := Config{Name: "job", Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}}
+	result, check := Run(ctx, cfg, []string{"sh", "-c", "sleep 10 & sleep 10"})
+	if result.ExitCode != -1 || check.AlertLevel != 2 || !strings.Contains(check.Message, "interrupted") {
+		t.Errorf("expected interrupted alert, got exit code %d level %d (%s)", result.ExitCode, check.AlertLevel, check.Message)
+	}
+}
+
+func TestRun_NoTailLines(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	tailLines := 0
+	cfg := Config{Name: "job", TailLines: &tailLines, Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}}
+	_, check := Run(context.Background(), cfg, []string{"sh", "-c", "echo oops >&2; exit 1"})
+	if strings.Contains(check.Message, "stderr") {
+		t.Errorf("expected no stderr in message, got %q", check.Message)
+	}
+}
+
+func TestRun_NotFound(t *testing.T) {
+	result, check := Run(context.Background(), Config{Name: "missing"}, []string{"/nonexistent/command"})
+	if result.Err == nil || check.AlertLevel != 2 || !strings.Contains(check.Message, "failed to run /nonexistent/command") {
+		t.Errorf("expected start failure alert, got level %d (%s)", check.AlertLevel, check.Message)
+	}
+}
+
+func TestTailWriter(t *testing.T) {
+	w := &tailWriter{max: 16}
+	w.Write([]byte("first line\nsecond\n"))
+	w.Write([]byte("third\n\nfourth\n"))
+
+	if got := w.lastLines(2); got != "third | fourth" {
+		t.Errorf("lastLines(2) = %q", got)
+	}
+	if len(w.buf) != 16 {
+		t.Errorf("expected buffer bounded to 16 bytes, got %d", len(w.buf))
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package execcheck
+
+import "os/exec"
+
+// killProcessGroup is a no-op where process groups aren't supported; only the
+// command itself is killed when the context is done
+func killProcessGroup(cmd *exec.Cmd) {}
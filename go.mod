@@ -5,6 +5,10 @@ go 1.24.11
 require (
 	github.com/shirou/gopsutil/v4 v4.25.12
 	github.com/urfave/cli/v3 v3.6.1
+	golang.org/x/crypto v0.44.0
+	golang.org/x/net v0.47.0
+	gopkg.in/yaml.v3 v3.0.1
+	software.sslmate.com/src/go-pkcs12 v0.5.0
 )
 
 require (
@@ -16,4 +20,5 @@ require (
 	github.com/tklauser/numcpus v0.11.0 // indirect
 	github.com/yusufpapurcu/wmi v1.2.4 // indirect
 	golang.org/x/sys v0.38.0 // indirect
+	golang.org/x/text v0.31.0 // indirect
 )
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/alertbingo/alertbingo/api"
 	"github.com/shirou/gopsutil/v4/cpu"
@@ -60,6 +61,16 @@ func Collect(ctx context.Context, cfg Config) ([]api.CheckPayload, error) {
 	return checks, nil
 }
 
+// Alert thresholds, shared by the comparisons and the measurements passed to templates
+const (
+	memoryWarnPercent = 95             // memory used at or above this warns
+	uptimeWarn        = 24 * time.Hour // uptime below this warns
+	cpuWarnPercent    = 100            // load at or above this warns
+	cpuMaxPercent     = 100            // load is reported up to this
+	diskWarnPercent   = 95             // disk or inode use over this warns
+	diskAlertPercent  = 99             // disk or inode use over this alerts
+)
+
 func collectMemory(ctx context.Context, cfg Config) (api.CheckPayload, error) {
 	vmem, err := mem.VirtualMemoryWithContext(ctx)
 	if err != nil {
@@ -68,9 +79,9 @@ func collectMemory(ctx context.Context, cfg Config) (api.CheckPayload, error) {
 	memPercent := int(math.Ceil(vmem.UsedPercent))
 	memAlertLevel := 0 // ok
 	memMessage := cfg.Message
-	if memPercent >= 95 {
+	if memPercent >= memoryWarnPercent {
 		memAlertLevel = 1 // warn
-		memMessage = appendAlertReason(cfg.Message, "Memory % over 95")
+		memMessage = appendAlertReason(cfg.Message, fmt.Sprintf("Memory %% over %d", memoryWarnPercent))
 	}
 	return api.CheckPayload{
 		Dashboard:        cfg.Dashboard,
@@ -83,6 +94,11 @@ func collectMemory(ctx context.Context, cfg Config) (api.CheckPayload, error) {
 		InactiveExpire:   cfg.InactiveExpire,
 		InactiveEscalate: cfg.InactiveEscalate,
 		Highlighted:      cfg.Highlighted,
+		Measurement: &api.Measurement{
+			Raw:        vmem.UsedPercent,
+			Values:     map[string]any{"used": vmem.Used, "total": vmem.Total, "available": vmem.Available},
+			Thresholds: map[string]any{"warn": memoryWarnPercent},
+		},
 	}, nil
 }
 
@@ -91,10 +107,11 @@ func collectUptime(ctx context.Context, cfg Config) (api.CheckPayload, error) {
 	if err != nil {
 		return api.CheckPayload{}, fmt.Errorf("failed to get host info: %w", err)
 	}
+	uptime := time.Duration(hostInfo.Uptime) * time.Second
 	uptimeDays := int(hostInfo.Uptime / 86400) // seconds to days
 	uptimeAlertLevel := 0                      // ok
 	uptimeMessage := cfg.Message
-	if uptimeDays < 1 {
+	if uptime < uptimeWarn {
 		uptimeAlertLevel = 1 // warn
 		uptimeMessage = appendAlertReason(cfg.Message, "Uptime less than 1 day")
 	}
@@ -109,6 +126,10 @@ func collectUptime(ctx context.Context, cfg Config) (api.CheckPayload, error) {
 		InactiveExpire:   cfg.InactiveExpire,
 		InactiveEscalate: cfg.InactiveEscalate,
 		Highlighted:      cfg.Highlighted,
+		Measurement: &api.Measurement{
+			Raw:        uptime,
+			Thresholds: map[string]any{"warn": uptimeWarn},
+		},
 	}, nil
 }
 
@@ -122,14 +143,14 @@ func collectCPU(ctx context.Context, cfg Config) (api.CheckPayload, error) {
 		return api.CheckPayload{}, fmt.Errorf("failed to get CPU count: %w", err)
 	}
 	cpuPercent := int(math.Ceil((loadAvg.Load1 / float64(cpuCount)) * 100))
-	if cpuPercent > 100 {
-		cpuPercent = 100 // cap at 100%
+	if cpuPercent > cpuMaxPercent {
+		cpuPercent = cpuMaxPercent // cap at 100%
 	}
 	cpuAlertLevel := 0 // ok
 	cpuMessage := cfg.Message
-	if cpuPercent == 100 {
+	if cpuPercent >= cpuWarnPercent {
 		cpuAlertLevel = 1 // warn
-		cpuMessage = appendAlertReason(cfg.Message, "CPU % at 100")
+		cpuMessage = appendAlertReason(cfg.Message, fmt.Sprintf("CPU %% at %d", cpuPercent))
 	}
 	return api.CheckPayload{
 		Dashboard:        cfg.Dashboard,
@@ -142,6 +163,11 @@ func collectCPU(ctx context.Context, cfg Config) (api.CheckPayload, error) {
 		InactiveExpire:   cfg.InactiveExpire,
 		InactiveEscalate: cfg.InactiveEscalate,
 		Highlighted:      cfg.Highlighted,
+		Measurement: &api.Measurement{
+			Raw:        loadAvg.Load1 / float64(cpuCount) * 100,
+			Values:     map[string]any{"load1": loadAvg.Load1, "load5": loadAvg.Load5, "load15": loadAvg.Load15, "cpus": cpuCount},
+			Thresholds: map[string]any{"warn": cpuWarnPercent},
+		},
 	}, nil
 }
 
@@ -155,12 +181,12 @@ func collectDisk(ctx context.Context, cfg Config) (api.CheckPayload, api.CheckPa
 	diskUsedPercent := int(math.Ceil(diskUsage.UsedPercent))
 	diskUsedAlertLevel := 0 // ok
 	diskUsedMessage := cfg.Message
-	if diskUsedPercent > 99 {
+	if diskUsedPercent > diskAlertPercent {
 		diskUsedAlertLevel = 2 // alert
-		diskUsedMessage = appendAlertReason(cfg.Message, "Disk Used % over 99")
-	} else if diskUsedPercent > 95 {
+		diskUsedMessage = appendAlertReason(cfg.Message, fmt.Sprintf("Disk Used %% over %d", diskAlertPercent))
+	} else if diskUsedPercent > diskWarnPercent {
 		diskUsedAlertLevel = 1 // warn
-		diskUsedMessage = appendAlertReason(cfg.Message, "Disk Used % over 95")
+		diskUsedMessage = appendAlertReason(cfg.Message, fmt.Sprintf("Disk Used %% over %d", diskWarnPercent))
 	}
 	diskUsedCheck := api.CheckPayload{
 		Dashboard:        cfg.Dashboard,
@@ -173,18 +199,24 @@ func collectDisk(ctx context.Context, cfg Config) (api.CheckPayload, api.CheckPa
 		InactiveExpire:   cfg.InactiveExpire,
 		InactiveEscalate: cfg.InactiveEscalate,
 		Highlighted:      cfg.Highlighted,
+		Measurement: &api.Measurement{
+			Raw:        diskUsage.UsedPercent,
+			Values:     map[string]any{"used": diskUsage.Used, "total": diskUsage.Total, "free": diskUsage.Free},
+			Thresholds: map[string]any{"warn": diskWarnPercent, "alert": diskAlertPercent},
+			Target:     diskUsage.Path,
+		},
 	}
 
 	// Disk Inodes check
 	diskInodesPercent := int(math.Ceil(diskUsage.InodesUsedPercent))
 	diskInodesAlertLevel := 0 // ok
 	diskInodesMessage := cfg.Message
-	if diskInodesPercent > 99 {
+	if diskInodesPercent > diskAlertPercent {
 		diskInodesAlertLevel = 2 // alert
-		diskInodesMessage = appendAlertReason(cfg.Message, "Disk Inodes % over 99")
-	} else if diskInodesPercent > 95 {
+		diskInodesMessage = appendAlertReason(cfg.Message, fmt.Sprintf("Disk Inodes %% over %d", diskAlertPercent))
+	} else if diskInodesPercent > diskWarnPercent {
 		diskInodesAlertLevel = 1 // warn
-		diskInodesMessage = appendAlertReason(cfg.Message, "Disk Inodes % over 95")
+		diskInodesMessage = appendAlertReason(cfg.Message, fmt.Sprintf("Disk Inodes %% over %d", diskWarnPercent))
 	}
 	diskInodesCheck := api.CheckPayload{
 		Dashboard:        cfg.Dashboard,
@@ -197,6 +229,12 @@ func collectDisk(ctx context.Context, cfg Config) (api.CheckPayload, api.CheckPa
 		InactiveExpire:   cfg.InactiveExpire,
 		InactiveEscalate: cfg.InactiveEscalate,
 		Highlighted:      cfg.Highlighted,
+		Measurement: &api.Measurement{
+			Raw:        diskUsage.InodesUsedPercent,
+			Values:     map[string]any{"used": diskUsage.InodesUsed, "total": diskUsage.InodesTotal, "free": diskUsage.InodesFree},
+			Thresholds: map[string]any{"warn": diskWarnPercent, "alert": diskAlertPercent},
+			Target:     diskUsage.Path,
+		},
 	}
 
 	return diskUsedCheck, diskInodesCheck, nil
@@ -0,0 +1,35 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	for _, content := range []string{"first", "second"} {
+		if err := Write(path, []byte(content)); err != nil {
+			t.Fatalf("Write(%q) error = %v", content, err)
+		}
+		if data, err := os.ReadFile(path); err != nil || string(data) != content {
+			t.Errorf("file = %q, %v, want %q", data, err, content)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the written file, found %d entries", len(entries))
+	}
+}
+
+func TestWrite_Error(t *testing.T) {
+	if err := Write(filepath.Join(t.TempDir(), "missing", "state.json"), []byte("x")); err == nil {
+		t.Error("expected an error writing into a missing directory")
+	}
+}
@@ -0,0 +1,80 @@
+// Package checkutil holds helpers shared by the check packages.
+package checkutil
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// AppendReason appends an alert reason to a message, separated by " - ".
+// Either may be empty.
+func AppendReason(message, reason string) string {
+	if message == "" {
+		return reason
+	}
+	if reason == "" {
+		return message
+	}
+	return message + " - " + reason
+}
+
+// LimitedBuffer keeps the first Max bytes written to it and discards the rest,
+// so that a command's output can't use unbounded memory
+type LimitedBuffer struct {
+	bytes.Buffer
+	Max       int
+	Truncated bool // more than Max bytes were written
+}
+
+func (b *LimitedBuffer) Write(p []byte) (int, error) {
+	room := b.Max - b.Len()
+	if room < len(p) {
+		b.Truncated = true
+	}
+	if room > 0 {
+		b.Buffer.Write(p[:min(room, len(p))])
+	}
+	return len(p), nil
+}
+
+// ParseHostOverride parses a host:port:address specification for connecting to
+// address instead of what host resolves to. An empty host or port matches any.
+// address is an IP address, which may be in brackets, or a hostname.
+func ParseHostOverride(spec string) (host, port, address string, err error) {
+	parts := strings.SplitN(spec, ":", 3)
+	if len(parts) != 3 {
+		return "", "", "", errors.New("must be host:port:address")
+	}
+
+	address = strings.TrimSuffix(strings.TrimPrefix(parts[2], "["), "]")
+	if address == "" {
+		return "", "", "", errors.New("must be host:port:address")
+	}
+
+	return parts[0], parts[1], address, nil
+}
+
+// ForEach calls fn for each index from 0 to n-1, with at most workers calls
+// running at once, and returns when they have all returned
+func ForEach(n, workers int, fn func(i int)) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+
+	for i := range n {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,70 @@
+package checkutil
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAppendReason(t *testing.T) {
+	tests := []struct {
+		message, reason, want string
+	}{
+		{"", "disk full", "disk full"},
+		{"Runbook", "disk full", "Runbook - disk full"},
+		{"Runbook", "", "Runbook"},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		if got := AppendReason(tt.message, tt.reason); got != tt.want {
+			t.Errorf("AppendReason(%q, %q) = %q, want %q", tt.message, tt.reason, got, tt.want)
+		}
+	}
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	b := &LimitedBuffer{Max: 5}
+	for _, s := range []string{"abc", "def", "ghi"} {
+		if n, err := b.Write([]byte(s)); n != len(s) || err != nil {
+			t.Fatalf("Write(%q) = %d, %v, want %d, nil", s, n, err, len(s))
+		}
+	}
+	if b.String() != "abcde" || !b.Truncated {
+		t.Errorf("buffer = %q truncated %v, want %q truncated", b.String(), b.Truncated, "abcde")
+	}
+
+	b = &LimitedBuffer{Max: 5}
+	b.Write([]byte("abcde"))
+	if b.Truncated {
+		t.Error("expected a buffer filled exactly not to be truncated")
+	}
+}
+
+func TestForEach(t *testing.T) {
+	var running, peak atomic.Int32
+	results := make([]string, 10)
+
+	ForEach(len(results), 3, func(i int) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		results[i] = strings.Repeat("x", i)
+		running.Add(-1)
+	})
+
+	if p := peak.Load(); p > 3 {
+		t.Errorf("expected at most 3 calls at once, got %d", p)
+	}
+	for i, r := range results {
+		if len(r) != i {
+			t.Errorf("results[%d] = %q, want fn called for every index", i, r)
+		}
+	}
+}
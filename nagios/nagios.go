@@ -0,0 +1,248 @@
+// Package nagios runs Nagios-compatible monitoring plugins and converts their results to checks.
+package nagios
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alertbingo/alertbingo/api"
+	"github.com/alertbingo/alertbingo/execcheck"
+	"github.com/alertbingo/alertbingo/internal/checkutil"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultTimeout is how long a plugin may run before it's killed
+const DefaultTimeout = 60 * time.Second
+
+// maxOutput bounds the plugin output read, as Nagios does
+const maxOutput = 8192
+
+// Plugin exit codes
+const (
+	StateOK       = 0
+	StateWarning  = 1
+	StateCritical = 2
+	StateUnknown  = 3
+)
+
+// stateNames are the plugin states by exit code
+var stateNames = []string{"OK", "WARNING", "CRITICAL", "UNKNOWN"}
+
+// Config holds the common configuration for plugin checks
+type Config struct {
+	Dashboard        string
+	Site             string
+	Service          string
+	Message          string
+	InactiveExpire   string
+	InactiveEscalate string
+	Highlighted      string
+	Timeout          time.Duration
+}
+
+// Plugin is a plugin invocation. Empty fields default to the Config's.
+type Plugin struct {
+	Name       string        `yaml:"name"`        // check name; defaults to the plugin's file name
+	Service    string        `yaml:"service"`     // service name
+	Command    []string      `yaml:"command"`     // plugin path and arguments
+	Timeout    time.Duration `yaml:"timeout"`     // e.g. 30s
+	ValueLabel string        `yaml:"value_label"` // performance data label reported as the value
+}
+
+// Result holds the outcome of running a plugin
+type Result struct {
+	ExitCode int
+	Output   string     // first line of output, without performance data
+	PerfData []PerfData // performance data from all lines of output
+	Err      error      // why the plugin didn't run or exit normally
+}
+
+// LoadPlugins reads a YAML list of plugin invocations from a file
+func LoadPlugins(path string) ([]Plugin, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugins file: %w", err)
+	}
+
+	var plugins []Plugin
+	if err := yaml.Unmarshal(data, &plugins); err != nil {
+		return nil, fmt.Errorf("failed to parse plugins file: %w", err)
+	}
+	for i, plugin := range plugins {
+		if len(plugin.Command) == 0 {
+			return nil, fmt.Errorf("plugin %d in %s has no command", i+1, path)
+		}
+	}
+
+	return plugins, nil
+}
+
+// RunAll runs the plugins with at most workers running at once, and returns
+// their checks in the same order
+func RunAll(ctx context.Context, cfg Config, plugins []Plugin, workers int) []api.CheckPayload {
+	checks := make([]api.CheckPayload, len(plugins))
+	checkutil.ForEach(len(plugins), workers, func(i int) {
+		checks[i] = Check(ctx, cfg, plugins[i])
+	})
+	return checks
+}
+
+// Check runs a plugin and returns its check
+func Check(ctx context.Context, cfg Config, plugin Plugin) api.CheckPayload {
+	return buildPayload(cfg, plugin, Run(ctx, cfg, plugin))
+}
+
+// Run runs a plugin and parses its output
+func Run(ctx context.Context, cfg Config, plugin Plugin) Result {
+	if len(plugin.Command) == 0 {
+		return Result{ExitCode: StateUnknown, Err: errors.New("no plugin command given")}
+	}
+
+	timeout := plugin.Timeout
+	if timeout == 0 {
+		timeout = cfg.Timeout
+	}
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stdout := &checkutil.LimitedBuffer{Max: maxOutput}
+	stderr := &checkutil.LimitedBuffer{Max: maxOutput}
+	cmd := execcheck.Command(ctx, plugin.Command)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err := cmd.Run()
+
+	// Plugins should only write to stdout, but fall back to stderr for
+	// errors such as a missing library
+	output := stdout.String()
+	if strings.TrimSpace(output) == "" {
+		output = stderr.String()
+	}
+	result := ParseOutput(output)
+	result.ExitCode = StateUnknown
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.Err = fmt.Errorf("plugin timed out after %s", timeout)
+	case err == nil:
+		result.ExitCode = StateOK
+	case errors.As(err, &exitErr) && exitErr.Exited():
+		result.ExitCode = exitErr.ExitCode()
+	case errors.As(err, &exitErr):
+		result.Err = fmt.Errorf("plugin killed: %s", exitErr.ProcessState)
+	case errors.Is(err, exec.ErrWaitDelay):
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	default:
+		result.Err = fmt.Errorf("failed to run %s: %w", plugin.Command[0], err)
+	}
+
+	return result
+}
+
+// ParseOutput parses plugin output: the first line is the status text, optionally
+// followed by | and performance data. Performance data may also follow a | on a
+// later line, after which every line is performance data.
+func ParseOutput(output string) Result {
+	var result Result
+	var perf []string
+
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	text, first, _ := strings.Cut(lines[0], "|")
+	result.Output = strings.TrimSpace(text)
+	perf = append(perf, first)
+
+	inPerf := false
+	for _, line := range lines[1:] {
+		if inPerf {
+			perf = append(perf, line)
+		} else if _, rest, ok := strings.Cut(line, "|"); ok {
+			inPerf = true
+			perf = append(perf, rest)
+		}
+	}
+
+	result.PerfData = ParsePerfData(strings.Join(perf, " "))
+	return result
+}
+
+// State returns the state name for an exit code, such as OK or CRITICAL
+func State(code int) string {
+	if code >= 0 && code < len(stateNames) {
+		return stateNames[code]
+	}
+	return stateNames[StateUnknown]
+}
+
+// alertLevel maps a plugin exit code to an alert level; unknown states alert
+func alertLevel(code int) int {
+	switch code {
+	case StateOK:
+		return 0
+	case StateWarning:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func buildPayload(cfg Config, plugin Plugin, result Result) api.CheckPayload {
+	name := plugin.Name
+	if name == "" && len(plugin.Command) > 0 {
+		name = filepath.Base(plugin.Command[0])
+	}
+	service := plugin.Service
+	if service == "" {
+		service = cfg.Service
+	}
+
+	level := 2
+	reason := result.Output
+	if result.Err != nil {
+		reason = checkutil.AppendReason(result.Err.Error(), result.Output)
+	} else {
+		level = alertLevel(result.ExitCode)
+		if reason == "" {
+			reason = fmt.Sprintf("%s: no output (exit code %d)", State(result.ExitCode), result.ExitCode)
+		}
+	}
+
+	value := State(result.ExitCode)
+	measurement := &api.Measurement{
+		Values: make(map[string]any, len(result.PerfData)),
+		Target: strings.Join(plugin.Command, " "),
+	}
+	for _, perf := range result.PerfData {
+		measurement.Values[perf.Label] = perf
+	}
+	if perf, ok := selectPerfData(result.PerfData, plugin.ValueLabel); ok {
+		value = perf.String()
+		if raw, ok := perf.Float(); ok {
+			measurement.Raw = raw
+		}
+		measurement.Thresholds = map[string]any{"warn": perf.Warn, "crit": perf.Crit, "min": perf.Min, "max": perf.Max}
+	}
+
+	return api.CheckPayload{
+		Dashboard:        cfg.Dashboard,
+		Site:             cfg.Site,
+		Service:          service,
+		Name:             name,
+		AlertLevel:       level,
+		Value:            value,
+		Message:          checkutil.AppendReason(cfg.Message, reason),
+		InactiveExpire:   cfg.InactiveExpire,
+		InactiveEscalate: cfg.InactiveEscalate,
+		Highlighted:      cfg.Highlighted,
+		Measurement:      measurement,
+	}
+}
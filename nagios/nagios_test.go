@@ -0,0 +1,165 @@
+package nagios
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseOutput(t *testing.T) {
+	output := "DISK OK - free space: / 3326 MB | /=2643MB;5948;5958\n" +
+		"/boot 68 MB (69%)\n" +
+		"/home 69357 MB (85%) | /boot=68MB;88;93\n" +
+		"/home=69357MB;253404;253409\n"
+
+	result := ParseOutput(output)
+	if result.Output != "DISK OK - free space: / 3326 MB" {
+		t.Errorf("unexpected output %q", result.Output)
+	}
+
+	var labels []string
+	for _, perf := range result.PerfData {
+		labels = append(labels, perf.Label)
+	}
+	if strings.Join(labels, ",") != "/,/boot,/home" {
+		t.Errorf("expected perfdata from all lines, got %v", labels)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	cfg := Config{Dashboard: "test", Site: "test-site", Service: "db1", Message: "MySQL"}
+
+	tests := []struct {
+		name      string
+		script    string
+		label     string
+		wantLevel int
+		wantValue string
+		wantMsg   string
+	}{
+		{"ok", "echo 'MYSQL OK - Uptime: 42 | connections=10;50;100 queries=7c'", "", 0, "10", "MySQL - MYSQL OK - Uptime: 42"},
+		{"warning with value label", "echo 'MYSQL WARNING | connections=60;50;100 queries=7c'; exit 1", "queries", 1, "7c", "MySQL - MYSQL WARNING"},
+		{"critical", "echo 'MYSQL CRITICAL - down'; exit 2", "", 2, "CRITICAL", "MySQL - MYSQL CRITICAL - down"},
+		{"unknown", "echo 'usage: check_mysql -H host'; exit 3", "", 2, "UNKNOWN", "MySQL - usage: check_mysql -H host"},
+		{"no output", "exit 1", "", 1, "WARNING", "MySQL - WARNING: no output (exit code 1)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := Plugin{Command: []string{"sh", "-c", tt.script}, ValueLabel: tt.label}
+			check := Check(context.Background(), cfg, plugin)
+			if check.AlertLevel != tt.wantLevel || check.Value != tt.wantValue || check.Message != tt.wantMsg {
+				t.Errorf("expected level %d value %q message %q, got level %d value %q message %q",
+					tt.wantLevel, tt.wantValue, tt.wantMsg, check.AlertLevel, check.Value, check.Message)
+			}
+			if check.Name != "sh" || check.Service != "db1" {
+				t.Errorf("expected name sh and service db1, got %q and %q", check.Name, check.Service)
+			}
+		})
+	}
+}
+
+func TestCheck_MeasurementRaw(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	cfg := Config{Dashboard: "test", Site: "test-site", Service: "db1"}
+
+	tests := []struct {
+		name   string
+		script string
+		want   any
+	}{
+		{"number", "echo 'OK | time=0.012s;1;5;0'", 0.012},
+		{"integer", "echo 'OK | connections=10;50;100'", 10.0},
+		{"undetermined", "echo 'UNKNOWN | time=U'; exit 3", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := Check(context.Background(), cfg, Plugin{Command: []string{"sh", "-c", tt.script}})
+			if check.Measurement == nil {
+				t.Fatal("expected a measurement")
+			}
+			if check.Measurement.Raw != tt.want {
+				t.Errorf("expected raw %v (%T), got %v (%T)", tt.want, tt.want, check.Measurement.Raw, check.Measurement.Raw)
+			}
+		})
+	}
+}
+
+func TestCheck_Timeout(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	plugin := Plugin{Name: "slow", Command: []string{"sh", "-c", "echo starting; sleep 10"}, Timeout: 100 * time.Millisecond}
+	check := Check(context.Background(), Config{}, plugin)
+	if check.AlertLevel != 2 || check.Message != "plugin timed out after 100ms - starting" {
+		t.Errorf("expected timeout alert, got level %d (%s)", check.AlertLevel, check.Message)
+	}
+}
+
+func TestRunAll(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	var plugins []Plugin
+	for i := 0; i < 4; i++ {
+		plugins = append(plugins, Plugin{Name: "slow", Command: []string{"sh", "-c", "sleep 0.2; echo OK"}})
+	}
+	plugins = append(plugins, Plugin{Name: "missing", Command: []string{"/nonexistent/check_x"}})
+
+	start := time.Now()
+	checks := RunAll(context.Background(), Config{}, plugins, 5)
+	if elapsed := time.Since(start); elapsed > 700*time.Millisecond {
+		t.Errorf("expected plugins to run concurrently, took %s", elapsed)
+	}
+	if len(checks) != len(plugins) {
+		t.Fatalf("expected %d checks, got %d", len(plugins), len(checks))
+	}
+	if checks[0].AlertLevel != 0 || checks[4].Name != "missing" || checks[4].AlertLevel != 2 {
+		t.Errorf("unexpected checks: %+v", checks)
+	}
+}
+
+func TestLoadPlugins(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := `- command: [/usr/lib/nagios/plugins/check_mysql, -H, db1]
+  service: db1
+  timeout: 30s
+- name: raid
+  command: [/usr/lib/nagios/plugins/check_raid]
+  value_label: degraded
+`
+	path := filepath.Join(dir, "plugins.yaml")
+	if err := os.WriteFile(path, []byte(valid), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins, err := LoadPlugins(path)
+	if err != nil {
+		t.Fatalf("LoadPlugins() error = %v", err)
+	}
+	if len(plugins) != 2 || plugins[0].Timeout != 30*time.Second || len(plugins[0].Command) != 3 || plugins[1].ValueLabel != "degraded" {
+		t.Errorf("unexpected plugins: %+v", plugins)
+	}
+
+	if err := os.WriteFile(path, []byte("- name: empty\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPlugins(path); err == nil {
+		t.Error("expected error for plugin without command")
+	}
+}
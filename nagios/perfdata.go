@@ -0,0 +1,122 @@
+package nagios
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PerfData is a plugin performance data metric, e.g. time=0.012s;1;5;0
+type PerfData struct {
+	Label string
+	Value string // number, or U if the value couldn't be determined
+	UOM   string // unit of measurement, e.g. s, %, B or c
+	Warn  string
+	Crit  string
+	Min   string
+	Max   string
+}
+
+// String returns the value with its unit, e.g. 0.012s
+func (p PerfData) String() string {
+	return p.Value + p.UOM
+}
+
+// Float returns the value as a number, or false if it isn't one, e.g. U
+func (p PerfData) Float() (float64, bool) {
+	value, err := strconv.ParseFloat(p.Value, 64)
+	return value, err == nil
+}
+
+// ParsePerfData parses space-separated 'label'=value[UOM];[warn];[crit];[min];[max]
+// metrics. Labels containing spaces are quoted with single quotes, doubling any
+// quote in the label. Malformed metrics are skipped.
+func ParsePerfData(s string) []PerfData {
+	var metrics []PerfData
+
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		var label string
+		if strings.HasPrefix(s, "'") {
+			label, s = parseQuotedLabel(s[1:])
+		} else {
+			i := strings.IndexAny(s, "= ")
+			if i < 0 {
+				break
+			}
+			label, s = s[:i], s[i:]
+		}
+
+		rest, ok := strings.CutPrefix(s, "=")
+		if !ok || label == "" {
+			// Skip to the next metric
+			_, s, _ = strings.Cut(s, " ")
+			continue
+		}
+		field, remainder, _ := strings.Cut(rest, " ")
+		s = remainder
+
+		if metric, ok := parseMetric(label, field); ok {
+			metrics = append(metrics, metric)
+		}
+	}
+
+	return metrics
+}
+
+// parseQuotedLabel returns the label up to the closing quote and the rest of s
+func parseQuotedLabel(s string) (string, string) {
+	var label strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\'' {
+			label.WriteByte(s[i])
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '\'' {
+			label.WriteByte('\'')
+			i++
+			continue
+		}
+		return label.String(), s[i+1:]
+	}
+	return label.String(), ""
+}
+
+// parseMetric parses value[UOM];[warn];[crit];[min];[max]
+func parseMetric(label, field string) (PerfData, bool) {
+	parts := strings.Split(field, ";")
+	metric := PerfData{Label: label}
+
+	value := parts[0]
+	end := len(value)
+	for end > 0 && !isNumeric(value[end-1]) {
+		end--
+	}
+	metric.Value, metric.UOM = value[:end], value[end:]
+	if metric.Value == "" {
+		if metric.UOM != "U" {
+			return PerfData{}, false
+		}
+		metric.Value, metric.UOM = "U", ""
+	}
+
+	for i, dst := range []*string{&metric.Warn, &metric.Crit, &metric.Min, &metric.Max} {
+		if i+1 < len(parts) {
+			*dst = parts[i+1]
+		}
+	}
+
+	return metric, true
+}
+
+func isNumeric(c byte) bool {
+	return (c >= '0' && c <= '9') || c == '.'
+}
+
+// selectPerfData returns the metric with the given label, or the first metric if label is empty
+func selectPerfData(metrics []PerfData, label string) (PerfData, bool) {
+	for _, metric := range metrics {
+		if label == "" || metric.Label == label {
+			return metric, true
+		}
+	}
+	return PerfData{}, false
+}
@@ -0,0 +1,31 @@
+package nagios
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePerfData(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []PerfData
+	}{
+		{"time=0.012s;1;5;0", []PerfData{{Label: "time", Value: "0.012", UOM: "s", Warn: "1", Crit: "5", Min: "0"}}},
+		{"'/ used'=81%;90;95 inodes=12", []PerfData{
+			{Label: "/ used", Value: "81", UOM: "%", Warn: "90", Crit: "95"},
+			{Label: "inodes", Value: "12"},
+		}},
+		{"'it''s'=-1.5KB", []PerfData{{Label: "it's", Value: "-1.5", UOM: "KB"}}},
+		{"users=U;;;0", []PerfData{{Label: "users", Value: "U", Min: "0"}}},
+		{"junk load=0.5 bad=abc", []PerfData{{Label: "load", Value: "0.5"}}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := ParsePerfData(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePerfData(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
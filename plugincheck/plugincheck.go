@@ -0,0 +1,286 @@
+// Package plugincheck runs external check plugins. A plugin is an executable,
+// written in any language, that reads a JSON Request on stdin and writes a JSON
+// array of checks to stdout.
+package plugincheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alertbingo/alertbingo/api"
+	"github.com/alertbingo/alertbingo/execcheck"
+	"github.com/alertbingo/alertbingo/internal/checkutil"
+	"gopkg.in/yaml.v3"
+)
+
+// ProtocolVersion is the version of the plugin protocol, sent in each Request
+const ProtocolVersion = 1
+
+// DefaultTimeout is how long a plugin may run before it's killed
+const DefaultTimeout = 30 * time.Second
+
+// DefaultDir is the directory plugins are looked up in
+const DefaultDir = "/etc/alertbingo/plugins"
+
+// maxOutput bounds the checks a plugin may write to stdout
+const maxOutput = 1 << 20
+
+// maxStderr bounds the plugin error output kept for failure messages
+const maxStderr = 4096
+
+// Config holds the common configuration for plugin checks. Checks a plugin
+// returns without a dashboard, site, service, inactivity or highlighted setting
+// get the Config's.
+type Config struct {
+	Dashboard        string
+	Site             string
+	Service          string
+	Message          string
+	InactiveExpire   string
+	InactiveEscalate string
+	Highlighted      string
+	Timeout          time.Duration
+}
+
+// Plugin is a plugin invocation
+type Plugin struct {
+	Name    string         `yaml:"name"`    // name of the check reporting a failure; defaults to the file name
+	Path    string         `yaml:"path"`    // plugin executable, or its name in the plugin directory
+	Args    []string       `yaml:"args"`    // plugin arguments
+	Timeout time.Duration  `yaml:"timeout"` // e.g. 30s; defaults to the Config's
+	Config  map[string]any `yaml:"config"`  // plugin configuration, sent in the Request
+}
+
+// Request is the JSON object a plugin reads from stdin
+type Request struct {
+	Protocol  int            `json:"protocol"`
+	Name      string         `json:"name"`
+	Dashboard string         `json:"dashboard,omitempty"`
+	Site      string         `json:"site,omitempty"`
+	Service   string         `json:"service,omitempty"`
+	Timeout   float64        `json:"timeout"` // seconds
+	Config    map[string]any `json:"config"`
+}
+
+// LoadPlugins reads a YAML list of plugin invocations from a file. Each path is
+// looked up with Find, so it may be a plugin name in dir.
+func LoadPlugins(path, dir string) ([]Plugin, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugins file: %w", err)
+	}
+
+	var plugins []Plugin
+	if err := yaml.Unmarshal(data, &plugins); err != nil {
+		return nil, fmt.Errorf("failed to parse plugins file: %w", err)
+	}
+	for i := range plugins {
+		if plugins[i].Path == "" {
+			return nil, fmt.Errorf("plugin %d in %s has no path", i+1, path)
+		}
+		if plugins[i].Path, err = Find(dir, plugins[i].Path); err != nil {
+			return nil, err
+		}
+	}
+
+	return plugins, nil
+}
+
+// Find returns the path of the named plugin. A name containing a path
+// separator is used as is; otherwise the plugin is looked up in dir.
+func Find(dir, name string) (string, error) {
+	path := name
+	if !strings.ContainsRune(name, filepath.Separator) && !strings.ContainsRune(name, '/') {
+		path = filepath.Join(dir, name)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("plugin %s not found: %w", name, err)
+	}
+	if !isExecutable(info) {
+		return "", fmt.Errorf("plugin %s is not an executable file", path)
+	}
+	return path, nil
+}
+
+// Discover returns the paths of the plugins in dir: its executable files, in
+// name order. Hidden files are skipped.
+func Discover(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin directory: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		info, err := os.Stat(path) // follow symlinks
+		if err != nil || !isExecutable(info) {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func isExecutable(info os.FileInfo) bool {
+	return info.Mode().IsRegular() && info.Mode().Perm()&0o111 != 0
+}
+
+// RunAll runs the plugins with at most workers running at once, and returns
+// their checks in plugin order
+func RunAll(ctx context.Context, cfg Config, plugins []Plugin, workers int) []api.CheckPayload {
+	results := make([][]api.CheckPayload, len(plugins))
+	checkutil.ForEach(len(plugins), workers, func(i int) {
+		results[i] = Check(ctx, cfg, plugins[i])
+	})
+
+	var checks []api.CheckPayload
+	for _, result := range results {
+		checks = append(checks, result...)
+	}
+	return checks
+}
+
+// Check runs a plugin and returns its checks. A plugin that fails, times out or
+// returns invalid checks is reported as a single alert.
+func Check(ctx context.Context, cfg Config, plugin Plugin) []api.CheckPayload {
+	checks, err := Run(ctx, cfg, plugin)
+	if err != nil {
+		return []api.CheckPayload{errorPayload(cfg, plugin, err)}
+	}
+	return checks
+}
+
+// Run runs a plugin and returns its checks, merged with the Config's defaults
+// and validated
+func Run(ctx context.Context, cfg Config, plugin Plugin) ([]api.CheckPayload, error) {
+	if plugin.Path == "" {
+		return nil, errors.New("no plugin path given")
+	}
+
+	timeout := plugin.Timeout
+	if timeout == 0 {
+		timeout = cfg.Timeout
+	}
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	config := plugin.Config
+	if config == nil {
+		config = map[string]any{}
+	}
+	request, err := json.Marshal(Request{
+		Protocol:  ProtocolVersion,
+		Name:      pluginName(plugin),
+		Dashboard: cfg.Dashboard,
+		Site:      cfg.Site,
+		Service:   cfg.Service,
+		Timeout:   timeout.Seconds(),
+		Config:    config,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plugin config: %w", err)
+	}
+
+	stdout := &checkutil.LimitedBuffer{Max: maxOutput}
+	stderr := &checkutil.LimitedBuffer{Max: maxStderr}
+	cmd := execcheck.Command(ctx, append([]string{plugin.Path}, plugin.Args...))
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err = cmd.Run()
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return nil, fmt.Errorf("plugin timed out after %s", timeout)
+	case err == nil, errors.Is(err, exec.ErrWaitDelay) && cmd.ProcessState.Success():
+		// Succeeded, even if something the plugin started kept the output open
+	case errors.As(err, &exitErr) && exitErr.Exited():
+		return nil, withStderr(fmt.Errorf("plugin exited with code %d", exitErr.ExitCode()), stderr)
+	case errors.As(err, &exitErr):
+		return nil, withStderr(fmt.Errorf("plugin killed: %s", exitErr.ProcessState), stderr)
+	default:
+		return nil, fmt.Errorf("failed to run %s: %w", plugin.Path, err)
+	}
+
+	if stdout.Truncated {
+		return nil, fmt.Errorf("plugin output exceeds %d bytes", maxOutput)
+	}
+	checks, err := api.ReadChecks(stdout)
+	if err != nil {
+		return nil, withStderr(fmt.Errorf("invalid plugin output: %w", err), stderr)
+	}
+	if len(checks) == 0 {
+		return nil, withStderr(errors.New("plugin returned no checks"), stderr)
+	}
+
+	checks = api.ApplyDefaults(checks, api.CheckPayload{
+		Dashboard:        cfg.Dashboard,
+		Site:             cfg.Site,
+		Service:          cfg.Service,
+		InactiveExpire:   cfg.InactiveExpire,
+		InactiveEscalate: cfg.InactiveEscalate,
+		Highlighted:      cfg.Highlighted,
+	})
+	if err := api.ValidateChecks(checks); err != nil {
+		return nil, fmt.Errorf("invalid plugin output: %w", err)
+	}
+	for i := range checks {
+		checks[i].Message = checkutil.AppendReason(cfg.Message, checks[i].Message)
+	}
+
+	return checks, nil
+}
+
+// pluginName returns the name of the check reporting a plugin failure
+func pluginName(plugin Plugin) string {
+	if plugin.Name != "" {
+		return plugin.Name
+	}
+	return filepath.Base(plugin.Path)
+}
+
+// errorPayload creates an alert for a plugin that failed
+func errorPayload(cfg Config, plugin Plugin, err error) api.CheckPayload {
+	return api.CheckPayload{
+		Dashboard:        cfg.Dashboard,
+		Site:             cfg.Site,
+		Service:          cfg.Service,
+		Name:             pluginName(plugin),
+		AlertLevel:       2, // alert
+		Value:            "Error",
+		Message:          checkutil.AppendReason(cfg.Message, strings.ReplaceAll(err.Error(), "\n", "; ")),
+		InactiveExpire:   cfg.InactiveExpire,
+		InactiveEscalate: cfg.InactiveEscalate,
+		Highlighted:      cfg.Highlighted,
+		Measurement:      &api.Measurement{Target: plugin.Path},
+	}
+}
+
+// withStderr adds the plugin's error output, joined onto one line, to err
+func withStderr(err error, stderr *checkutil.LimitedBuffer) error {
+	text := strings.TrimSpace(stderr.String())
+	if text == "" {
+		return err
+	}
+	return fmt.Errorf("%w; stderr: %s", err, strings.ReplaceAll(text, "\n", " | "))
+}
@@ -0,0 +1,193 @@
+package plugincheck
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writePlugin writes an executable shell script plugin to dir
+func writePlugin(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	requestFile := filepath.Join(dir, "request.json")
+	path := writePlugin(t, dir, "queue", `cat > `+requestFile+`
+echo '[{"name": "depth", "alert_level": "warn", "value": "120", "message": "queue backing up"},'
+echo ' {"service": "worker", "name": "consumers", "alert_level": 0, "dashboard": "Jobs"}]'`)
+
+	cfg := Config{Dashboard: "D", Site: "S", Service: "queue", Message: "Runbook", Highlighted: "true"}
+	plugin := Plugin{Path: path, Timeout: 5 * time.Second, Config: map[string]any{"queue": "emails", "max": 100}}
+
+	checks, err := Run(context.Background(), cfg, plugin)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(checks) != 2 {
+		t.Fatalf("Run() returned %d checks, want 2", len(checks))
+	}
+
+	depth := checks[0]
+	if depth.Dashboard != "D" || depth.Site != "S" || depth.Service != "queue" || depth.Highlighted != "true" {
+		t.Errorf("depth = %+v, want defaults merged", depth)
+	}
+	if depth.AlertLevel != 1 || depth.Value != "120" || depth.Message != "Runbook - queue backing up" {
+		t.Errorf("depth = %+v, want warn with plugin message", depth)
+	}
+	if consumers := checks[1]; consumers.Dashboard != "Jobs" || consumers.Service != "worker" {
+		t.Errorf("consumers = %+v, want plugin settings kept", consumers)
+	}
+
+	request, err := os.ReadFile(requestFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"protocol":1,"name":"queue","dashboard":"D","site":"S","service":"queue","timeout":5,"config":{"max":100,"queue":"emails"}}`
+	if string(request) != want {
+		t.Errorf("request = %s, want %s", request, want)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		script  string
+		timeout time.Duration
+		wantErr string
+	}{
+		{"config", `grep -q '"config":{}' || exit 4; echo '[]'`, 0, "plugin returned no checks"},
+		{"exit code", "echo 'no such queue' >&2; exit 3", 0, "plugin exited with code 3; stderr: no such queue"},
+		{"timeout", "sleep 5", 100 * time.Millisecond, "plugin timed out after 100ms"},
+		{"not json", "echo OK", 0, "invalid plugin output"},
+		{"no checks", "echo '[]'", 0, "plugin returned no checks"},
+		{"missing level", `echo '[{"name": "x"}]'`, 0, "missing alert_level"},
+		{"unknown field", `echo '[{"name": "x", "alert_level": 0, "level": 1}]'`, 0, "unknown field"},
+		{"invalid check", `echo '[{"alert_level": 0}]'`, 0, "invalid plugin output: check 1 (): missing name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writePlugin(t, dir, strings.ReplaceAll(tt.name, " ", "_"), tt.script)
+			cfg := Config{Dashboard: "D", Site: "S", Service: "svc"}
+
+			_, err := Run(context.Background(), cfg, Plugin{Path: path, Timeout: tt.timeout})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Run() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunAll(t *testing.T) {
+	dir := t.TempDir()
+	ok := writePlugin(t, dir, "ok", `echo '[{"name": "a", "alert_level": "ok"}, {"name": "b", "alert_level": "ok"}]'`)
+	failing := writePlugin(t, dir, "failing", "exit 1")
+
+	cfg := Config{Dashboard: "D", Site: "S", Service: "svc", Message: "See wiki"}
+	checks := RunAll(context.Background(), cfg, []Plugin{{Path: ok}, {Path: failing, Name: "broken"}}, 2)
+
+	var names []string
+	for _, check := range checks {
+		names = append(names, check.Name)
+	}
+	if want := []string{"a", "b", "broken"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("RunAll() names = %v, want %v", names, want)
+	}
+
+	broken := checks[2]
+	if broken.AlertLevel != 2 || broken.Value != "Error" || broken.Service != "svc" || broken.Message != "See wiki - plugin exited with code 1" {
+		t.Errorf("broken = %+v, want alert for the failed plugin", broken)
+	}
+}
+
+func TestFindAndDiscover(t *testing.T) {
+	dir := t.TempDir()
+	b := writePlugin(t, dir, "b", "true")
+	a := writePlugin(t, dir, "a", "true")
+	writePlugin(t, dir, ".hidden", "true")
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("not a plugin"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "lib"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := Discover(dir)
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if want := []string{a, b}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Discover() = %v, want %v", paths, want)
+	}
+
+	if path, err := Find(dir, "a"); err != nil || path != a {
+		t.Errorf("Find(a) = %q, %v, want %q", path, err, a)
+	}
+	if path, err := Find("/elsewhere", b); err != nil || path != b {
+		t.Errorf("Find(%s) = %q, %v, want the path", b, path, err)
+	}
+	if _, err := Find(dir, "README"); err == nil || !strings.Contains(err.Error(), "not an executable") {
+		t.Errorf("Find(README) error = %v, want not an executable", err)
+	}
+	if _, err := Find(dir, "missing"); err == nil {
+		t.Error("Find(missing) error = nil, want not found")
+	}
+}
+
+func TestLoadPlugins(t *testing.T) {
+	dir := t.TempDir()
+	queue := writePlugin(t, dir, "queue", "true")
+	file := filepath.Join(t.TempDir(), "plugins.yaml")
+	content := `
+- path: queue
+  name: emails
+  timeout: 5s
+  config:
+    queue: emails
+    thresholds: {warn: 100, alert: 1000}
+- path: ` + queue + `
+  args: [--verbose]
+`
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins, err := LoadPlugins(file, dir)
+	if err != nil {
+		t.Fatalf("LoadPlugins() error = %v", err)
+	}
+	want := []Plugin{
+		{Name: "emails", Path: queue, Timeout: 5 * time.Second, Config: map[string]any{
+			"queue":      "emails",
+			"thresholds": map[string]any{"warn": 100, "alert": 1000},
+		}},
+		{Path: queue, Args: []string{"--verbose"}},
+	}
+	if !reflect.DeepEqual(plugins, want) {
+		t.Errorf("LoadPlugins() = %+v, want %+v", plugins, want)
+	}
+
+	if err := os.WriteFile(file, []byte("- path: missing\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPlugins(file, dir); err == nil || !strings.Contains(err.Error(), "plugin missing not found") {
+		t.Errorf("LoadPlugins() error = %v, want not found", err)
+	}
+}
@@ -0,0 +1,65 @@
+// Package proxyconfig builds HTTP proxy functions for HTTP CONNECT and SOCKS5 proxies.
+package proxyconfig
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// Options holds the proxy settings shared by the URL checks and the API client
+type Options struct {
+	URL      string `yaml:"url"`      // http://, https://, socks5:// or socks5h:// proxy URL
+	NoProxy  string `yaml:"no_proxy"` // comma-separated hosts, domains and CIDRs to connect to directly, as in NO_PROXY
+	Username string `yaml:"username"` // proxy username; overrides any in URL
+	Password string `yaml:"password"` // proxy password; overrides any in URL
+}
+
+// IsZero reports whether no proxy is set, i.e. the environment's proxy settings apply
+func (o Options) IsZero() bool {
+	return o.URL == ""
+}
+
+// Func returns a proxy function for http.Transport.Proxy, or nil if no proxy is set.
+// Requests to hosts matching NoProxy, and to localhost and loopback addresses, are
+// not proxied.
+func (o Options) Func() (func(*http.Request) (*url.URL, error), error) {
+	if o.IsZero() {
+		return nil, nil
+	}
+
+	proxyURL, err := url.Parse(o.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL: %s (scheme must be http, https, socks5 or socks5h)", o.URL)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL: %s (missing host)", o.URL)
+	}
+
+	if o.Username != "" {
+		proxyURL.User = url.UserPassword(o.Username, o.Password)
+	}
+
+	// httpproxy only decides whether to bypass the proxy, as it doesn't
+	// understand every scheme the transport supports
+	cfg := httpproxy.Config{
+		HTTPProxy:  "http://proxy",
+		HTTPSProxy: "http://proxy",
+		NoProxy:    o.NoProxy,
+	}
+	proxyFunc := cfg.ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		if u, err := proxyFunc(req.URL); err != nil || u == nil {
+			return nil, err
+		}
+		return proxyURL, nil
+	}, nil
+}
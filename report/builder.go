@@ -0,0 +1,93 @@
+package report
+
+import (
+	"fmt"
+
+	"github.com/alertbingo/alertbingo/api"
+)
+
+// Builder builds a check for a Reporter. Its methods return the Builder so calls
+// can be chained, ending with Send.
+type Builder struct {
+	reporter *Reporter
+	payload  api.CheckPayload
+}
+
+// Dashboard sets the dashboard, overriding the Reporter's default
+func (b *Builder) Dashboard(dashboard string) *Builder {
+	b.payload.Dashboard = dashboard
+	return b
+}
+
+// Site sets the site, overriding the Reporter's default
+func (b *Builder) Site(site string) *Builder {
+	b.payload.Site = site
+	return b
+}
+
+// Service sets the service, overriding the Reporter's default
+func (b *Builder) Service(service string) *Builder {
+	b.payload.Service = service
+	return b
+}
+
+// OK sets the check to ok with an optional message
+func (b *Builder) OK(message string) *Builder {
+	return b.Level(0, message)
+}
+
+// Warn sets the check to warn with a message
+func (b *Builder) Warn(message string) *Builder {
+	return b.Level(1, message)
+}
+
+// Alert sets the check to alert with a message
+func (b *Builder) Alert(message string) *Builder {
+	return b.Level(2, message)
+}
+
+// Level sets the alert level (0 ok, 1 warn, 2 alert) and message
+func (b *Builder) Level(level int, message string) *Builder {
+	b.payload.AlertLevel = level
+	b.payload.Message = message
+	return b
+}
+
+// Value sets the short-form value, e.g. 30s or 95%
+func (b *Builder) Value(value string) *Builder {
+	b.payload.Value = value
+	return b
+}
+
+// Valuef sets the value from a format string
+func (b *Builder) Valuef(format string, args ...any) *Builder {
+	return b.Value(fmt.Sprintf(format, args...))
+}
+
+// InactiveExpire sets how long the check may go unreported before it expires, e.g. 48h
+func (b *Builder) InactiveExpire(duration string) *Builder {
+	b.payload.InactiveExpire = duration
+	return b
+}
+
+// InactiveEscalate sets how long the check may go unreported before it escalates, e.g. 1h
+func (b *Builder) InactiveEscalate(duration string) *Builder {
+	b.payload.InactiveEscalate = duration
+	return b
+}
+
+// Highlighted sets the highlighted status
+func (b *Builder) Highlighted(highlighted string) *Builder {
+	b.payload.Highlighted = highlighted
+	return b
+}
+
+// Payload returns the check built so far
+func (b *Builder) Payload() api.CheckPayload {
+	return b.payload
+}
+
+// Send validates the check and queues it on the Reporter
+func (b *Builder) Send() error {
+	return b.reporter.Report(b.payload)
+}
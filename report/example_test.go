@@ -0,0 +1,36 @@
+package report_test
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/alertbingo/alertbingo/report"
+)
+
+func Example() {
+	reporter := report.New(report.Options{
+		Token:            os.Getenv("ALERTBINGO_TOKEN"),
+		Dashboard:        "Payments",
+		Site:             "payments-prod",
+		Service:          "postgres",
+		InactiveEscalate: "30m",
+		FlushInterval:    30 * time.Second,
+	})
+
+	lag := 30 * time.Second
+	check := reporter.Check("replication-lag").Value(lag.String())
+	if lag > 10*time.Second {
+		check.Warn("replica is behind")
+	}
+	if err := check.Send(); err != nil {
+		log.Printf("alertbingo: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := reporter.Close(ctx); err != nil {
+		log.Printf("alertbingo: %v", err)
+	}
+}
@@ -0,0 +1,241 @@
+// Package report lets Go programs report checks to Alert Bingo.
+//
+// A Reporter holds the default dashboard, site and inactivity settings for its
+// checks and sends them in batches in the background:
+//
+//	reporter := report.New(report.Options{
+//		Token:     os.Getenv("ALERTBINGO_TOKEN"),
+//		Dashboard: "Payments",
+//		Site:      "payments-prod",
+//		Service:   "postgres",
+//	})
+//	if err := reporter.Check("replication-lag").Warn("lag 30s").Value("30s").Send(); err != nil {
+//		log.Print(err)
+//	}
+//
+//	// On shutdown, send the checks still queued, giving up after 5 seconds
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	if err := reporter.Close(ctx); err != nil {
+//		log.Print(err)
+//	}
+//
+// Checks are queued by Send and sent every flush interval, or as soon as a
+// batch is full. Close sends any queued checks before returning.
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alertbingo/alertbingo/api"
+)
+
+// DefaultFlushInterval is how often queued checks are sent when no interval is set
+const DefaultFlushInterval = 10 * time.Second
+
+// ErrClosed is returned when reporting to a closed Reporter
+var ErrClosed = errors.New("reporter is closed")
+
+// Options configures a Reporter. The dashboard, site, service and inactivity
+// settings are defaults for checks that don't set their own.
+type Options struct {
+	APIURL string      // defaults to api.DefaultAPIURL
+	Token  string      // API bearer token
+	Client *api.Client // client to send with instead of one for APIURL and Token
+
+	Dashboard        string
+	Site             string
+	Service          string
+	InactiveExpire   string // e.g. 48h or 30m
+	InactiveEscalate string // e.g. 1h or 30m
+	Highlighted      string
+
+	FlushInterval time.Duration // how often queued checks are sent; defaults to DefaultFlushInterval
+	BatchSize     int           // checks per request; a full batch is sent straight away. Defaults to api.DefaultBatchSize
+	OnError       func(error)   // called when a background send fails; the failed checks are dropped
+}
+
+// Reporter queues checks and sends them to the API in the background. It is
+// safe for concurrent use.
+type Reporter struct {
+	client   *api.Client
+	defaults api.CheckPayload
+	batch    int
+	onError  func(error)
+
+	mu     sync.Mutex
+	queue  []api.CheckPayload
+	closed bool
+
+	sendMu sync.Mutex // keeps batches in the order they were queued
+	full   chan struct{}
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// New creates a Reporter and starts sending queued checks in the background
+func New(opts Options) *Reporter {
+	client := opts.Client
+	if client == nil {
+		apiURL := opts.APIURL
+		if apiURL == "" {
+			apiURL = api.DefaultAPIURL
+		}
+		client = api.NewClient(apiURL, opts.Token)
+	}
+	interval := opts.FlushInterval
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+	batch := opts.BatchSize
+	if batch < 1 {
+		batch = api.DefaultBatchSize
+	}
+
+	r := &Reporter{
+		client: client,
+		defaults: api.CheckPayload{
+			Dashboard:        opts.Dashboard,
+			Site:             opts.Site,
+			Service:          opts.Service,
+			InactiveExpire:   opts.InactiveExpire,
+			InactiveEscalate: opts.InactiveEscalate,
+			Highlighted:      opts.Highlighted,
+		},
+		batch:   batch,
+		onError: opts.OnError,
+		full:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+
+	r.wg.Add(1)
+	go r.run(interval)
+
+	return r
+}
+
+// run sends queued checks every interval, or when a batch is full, until Close
+func (r *Reporter) run(interval time.Duration) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		case <-r.full:
+		}
+
+		if err := r.Flush(context.Background()); err != nil && r.onError != nil {
+			r.onError(err)
+		}
+	}
+}
+
+// Check starts building a check with the given name and the Reporter's defaults.
+// The check is ok until a level is set.
+func (r *Reporter) Check(name string) *Builder {
+	payload := r.defaults
+	payload.Name = name
+	return &Builder{reporter: r, payload: payload}
+}
+
+// Report validates and queues checks, filling in the Reporter's defaults for
+// fields they don't set. No checks are queued if any is invalid.
+func (r *Reporter) Report(checks ...api.CheckPayload) error {
+	checks = api.ApplyDefaults(append([]api.CheckPayload(nil), checks...), r.defaults)
+	if err := api.ValidateChecks(checks); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrClosed
+	}
+	r.queue = append(r.queue, checks...)
+	if len(r.queue) >= r.batch {
+		select {
+		case r.full <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Flush sends the queued checks now, in batches. Every batch is attempted even
+// if an earlier one fails; checks in batches that fail to send are dropped.
+func (r *Reporter) Flush(ctx context.Context) error {
+	r.sendMu.Lock()
+	defer r.sendMu.Unlock()
+
+	r.mu.Lock()
+	checks := r.queue
+	r.queue = nil
+	r.mu.Unlock()
+
+	if len(checks) == 0 {
+		return nil
+	}
+
+	var errs []error
+	var responses []api.Response
+	for start := 0; start < len(checks); start += r.batch {
+		end := min(start+r.batch, len(checks))
+		batch, err := r.client.SendChecks(ctx, checks[start:end])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to send checks %d-%d of %d: %w", start+1, end, len(checks), err))
+			continue
+		}
+		responses = append(responses, batch...)
+	}
+
+	return errors.Join(append(errs, responseError(responses))...)
+}
+
+// Close stops the background sending and sends any queued checks, giving up
+// when ctx is done. Reporting after Close returns ErrClosed. Close may be called
+// more than once.
+func (r *Reporter) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	r.mu.Unlock()
+
+	close(r.done)
+	r.wg.Wait()
+
+	return r.Flush(ctx)
+}
+
+// responseError returns an error listing the checks the API didn't accept, if any
+func responseError(responses []api.Response) error {
+	var problems []string
+	for _, resp := range responses {
+		if strings.EqualFold(resp.Status, "OK") {
+			continue
+		}
+		problem := resp.Status
+		if len(resp.Errors) > 0 {
+			problem += ": " + strings.Join(resp.Errors, ", ")
+		}
+		problems = append(problems, problem)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d checks not accepted: %s", len(problems), strings.Join(problems, "; "))
+}
@@ -0,0 +1,221 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alertbingo/alertbingo/api"
+)
+
+// recorder is a fake API that records the batches it receives
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]api.CheckPayload
+	status  string
+	fail    int // request number, from 1, to fail with a server error
+}
+
+func newRecorder(t *testing.T) (*recorder, *httptest.Server) {
+	t.Helper()
+
+	rec := &recorder{status: "OK"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var checks []api.CheckPayload
+		json.NewDecoder(r.Body).Decode(&checks)
+
+		rec.mu.Lock()
+		rec.batches = append(rec.batches, checks)
+		if len(rec.batches) == rec.fail {
+			rec.mu.Unlock()
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		responses := make([]api.Response, len(checks))
+		for i := range responses {
+			responses[i].Status = rec.status
+		}
+		rec.mu.Unlock()
+
+		json.NewEncoder(w).Encode(responses)
+	}))
+	t.Cleanup(server.Close)
+
+	return rec, server
+}
+
+func (rec *recorder) sent() [][]api.CheckPayload {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([][]api.CheckPayload(nil), rec.batches...)
+}
+
+func TestBuilder(t *testing.T) {
+	reporter := New(Options{Token: "t", Dashboard: "dash", Site: "site", Service: "svc", InactiveExpire: "48h"})
+	defer reporter.Close(context.Background())
+
+	got := reporter.Check("db").Warn("lag 30s").Valuef("%ds", 30).Service("replica").Payload()
+	want := api.CheckPayload{
+		Dashboard:      "dash",
+		Site:           "site",
+		Service:        "replica",
+		Name:           "db",
+		AlertLevel:     1,
+		Message:        "lag 30s",
+		Value:          "30s",
+		InactiveExpire: "48h",
+	}
+	if got != want {
+		t.Errorf("Payload() = %+v, want %+v", got, want)
+	}
+}
+
+func TestReporter_Close(t *testing.T) {
+	rec, server := newRecorder(t)
+	reporter := New(Options{APIURL: server.URL, Token: "t", Dashboard: "dash", Site: "site", Service: "svc", FlushInterval: time.Hour})
+
+	if err := reporter.Check("a").OK("").Send(); err != nil {
+		t.Fatal(err)
+	}
+	if err := reporter.Report(api.CheckPayload{Name: "b", Site: "other", AlertLevel: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reporter.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	batches := rec.sent()
+	if len(batches) != 1 || len(batches[0]) != 2 || batches[0][1].Site != "other" || batches[0][1].Dashboard != "dash" {
+		t.Errorf("expected queued checks to be sent on Close, got %+v", batches)
+	}
+
+	if err := reporter.Check("c").Send(); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed after Close, got %v", err)
+	}
+	if err := reporter.Close(context.Background()); err != nil {
+		t.Errorf("expected second Close to succeed, got %v", err)
+	}
+}
+
+func TestReporter_CloseDeadline(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+	reporter := New(Options{APIURL: server.URL, Token: "t", Dashboard: "dash", Site: "site", Service: "svc", FlushInterval: time.Hour})
+
+	if err := reporter.Check("a").OK("").Send(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := reporter.Close(ctx); err == nil {
+		t.Error("expected Close to fail when the API does not answer before the deadline")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Close took %v, expected it to give up at the deadline", elapsed)
+	}
+}
+
+func TestReporter_Background(t *testing.T) {
+	rec, server := newRecorder(t)
+	reporter := New(Options{APIURL: server.URL, Token: "t", Dashboard: "dash", Site: "site", Service: "svc", FlushInterval: 20 * time.Millisecond, BatchSize: 2})
+	defer reporter.Close(context.Background())
+
+	waitFor := func(n int) [][]api.CheckPayload {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if batches := rec.sent(); len(batches) >= n {
+				return batches
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Fatalf("timed out waiting for %d batches, got %d", n, len(rec.sent()))
+		return nil
+	}
+
+	// Sent on the flush interval
+	reporter.Check("a").Send()
+	if batches := waitFor(1); len(batches[0]) != 1 {
+		t.Errorf("expected 1 check in the first batch, got %d", len(batches[0]))
+	}
+
+	// Sent in batches of at most 2
+	for _, name := range []string{"b", "c", "d"} {
+		reporter.Check(name).Send()
+	}
+	batches := waitFor(3)
+	total := 0
+	for _, batch := range batches[1:] {
+		if len(batch) > 2 {
+			t.Errorf("expected batches of at most 2 checks, got %d", len(batch))
+		}
+		total += len(batch)
+	}
+	if total != 3 {
+		t.Errorf("expected 3 more checks sent, got %d", total)
+	}
+}
+
+func TestReporter_Errors(t *testing.T) {
+	rec, server := newRecorder(t)
+	rec.status = "ERROR"
+
+	reporter := New(Options{APIURL: server.URL, Token: "t", Dashboard: "dash", FlushInterval: time.Hour})
+	defer reporter.Close(context.Background())
+
+	if err := reporter.Check("a").Send(); err == nil || !strings.Contains(err.Error(), "missing site, service") {
+		t.Errorf("expected validation error, got %v", err)
+	}
+
+	reporter.Check("a").Site("site").Service("svc").Alert("down").Send()
+	if err := reporter.Flush(t.Context()); err == nil || !strings.Contains(err.Error(), "1 checks not accepted: ERROR") {
+		t.Errorf("expected rejected check error, got %v", err)
+	}
+}
+
+func TestReporter_FlushFailedBatch(t *testing.T) {
+	rec, server := newRecorder(t)
+	rec.fail = 2
+	reporter := New(Options{APIURL: server.URL, Token: "t", Dashboard: "dash", Site: "site", Service: "svc", FlushInterval: time.Hour, BatchSize: 100})
+
+	var checks []api.CheckPayload
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		checks = append(checks, api.CheckPayload{Name: name})
+	}
+	if err := reporter.Report(checks...); err != nil {
+		t.Fatal(err)
+	}
+	reporter.batch = 2 // send the five queued checks in three batches
+
+	err := reporter.Flush(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "failed to send checks 3-4 of 5") {
+		t.Errorf("Flush() error = %v, want the failed batch", err)
+	}
+
+	var names []string
+	for _, batch := range rec.sent() {
+		for _, check := range batch {
+			names = append(names, check.Name)
+		}
+	}
+	if got := strings.Join(names, ","); got != "a,b,c,d,e" {
+		t.Errorf("sent %s, want every batch attempted after the failure", got)
+	}
+
+	reporter.Close(context.Background())
+	if len(rec.sent()) != 3 {
+		t.Errorf("expected the failed checks to be dropped, got %d requests", len(rec.sent()))
+	}
+}
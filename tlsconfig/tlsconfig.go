@@ -0,0 +1,74 @@
+// Package tlsconfig builds TLS client configurations from CA bundles and client certificates.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// Options holds the TLS client settings shared by the certificate and URL checks
+type Options struct {
+	CAFile             string `yaml:"ca_file"`              // PEM bundle of additional trusted CAs
+	ClientCert         string `yaml:"client_cert"`          // PEM client certificate for mutual TLS
+	ClientKey          string `yaml:"client_key"`           // PEM private key for the client certificate
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"` // skip certificate chain and hostname verification
+}
+
+// IsZero reports whether no options are set, i.e. the default TLS configuration applies
+func (o Options) IsZero() bool {
+	return o == Options{}
+}
+
+// Build returns a TLS client configuration for the options, or nil if none are set.
+// CAs from CAFile are trusted in addition to the system roots.
+func (o Options) Build() (*tls.Config, error) {
+	if o.IsZero() {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if o.CAFile != "" {
+		pool, err := loadCAFile(o.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+
+	if o.ClientCert != "" || o.ClientKey != "" {
+		if o.ClientCert == "" || o.ClientKey == "" {
+			return nil, fmt.Errorf("both client certificate and client key are required")
+		}
+		cert, err := tls.LoadX509KeyPair(o.ClientCert, o.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// loadCAFile returns the system root pool with the CAs from path appended
+func loadCAFile(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA file %s", path)
+	}
+
+	return pool, nil
+}
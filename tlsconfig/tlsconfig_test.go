@@ -0,0 +1,68 @@
+package tlsconfig
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuild_Zero(t *testing.T) {
+	cfg, err := Options{}.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if cfg != nil {
+		t.Errorf("expected nil config for zero options, got %+v", cfg)
+	}
+}
+
+func TestBuild_CAFile(t *testing.T) {
+	server := httptest.NewTLSServer(nil)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Options{CAFile: path}.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if cfg.RootCAs == nil {
+		t.Fatal("expected RootCAs to be set")
+	}
+	if _, err := server.Certificate().Verify(x509.VerifyOptions{Roots: cfg.RootCAs, DNSName: "example.com"}); err != nil {
+		t.Errorf("expected server certificate to verify against CA file: %v", err)
+	}
+}
+
+func TestBuild_Errors(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"missing CA file", Options{CAFile: filepath.Join(dir, "missing.pem")}},
+		{"CA file without certificates", Options{CAFile: empty}},
+		{"client cert without key", Options{ClientCert: empty}},
+		{"invalid client key pair", Options{ClientCert: empty, ClientKey: empty}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.opts.Build(); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
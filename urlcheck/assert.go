@@ -0,0 +1,348 @@
+package urlcheck
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// assertionOperators lists the supported comparison operators; longer operators
+// come first so that "<=" is not parsed as "<"
+var assertionOperators = []string{"==", "!=", "=~", "<=", ">=", "<", ">"}
+
+// parseAssertion parses "<subject> <op> <expected>", "<subject> exists" or
+// "<subject> !exists", and compiles the pattern of an =~ assertion. kind names
+// the assertion type in error messages.
+func parseAssertion(kind, spec string) (subject, op, expected string, regex *regexp.Regexp, err error) {
+	spec = strings.TrimSpace(spec)
+
+	for _, op := range []string{"!exists", "exists"} {
+		if subject, ok := strings.CutSuffix(spec, " "+op); ok {
+			return strings.TrimSpace(subject), op, "", nil, nil
+		}
+	}
+
+	// Find the earliest operator in the spec
+	index := -1
+	for _, candidate := range assertionOperators {
+		if i := strings.Index(spec, candidate); i > 0 && (index == -1 || i < index) {
+			index, op = i, candidate
+		}
+	}
+	if index == -1 {
+		return "", "", "", nil, fmt.Errorf("invalid %s assertion: %s (expected \"<path> <op> <value>\" or \"<path> exists\")", kind, spec)
+	}
+
+	subject = strings.TrimSpace(spec[:index])
+	expected = strings.TrimSpace(spec[index+len(op):])
+
+	if op == "=~" {
+		if regex, err = regexp.Compile(expected); err != nil {
+			return "", "", "", nil, fmt.Errorf("invalid regex in %s assertion %q: %w", kind, spec, err)
+		}
+	}
+	if isNumericOp(op) {
+		if _, err := strconv.ParseFloat(expected, 64); err != nil {
+			return "", "", "", nil, fmt.Errorf("invalid number in %s assertion %q", kind, spec)
+		}
+	}
+
+	return subject, op, expected, regex, nil
+}
+
+// formatAssertion returns an assertion in its parseable form
+func formatAssertion(subject, op, expected string) string {
+	if op == "exists" || op == "!exists" {
+		return subject + " " + op
+	}
+	return subject + " " + op + " " + expected
+}
+
+// evaluateAssertion applies op to the actual value (if found) and returns a
+// description of the failure prefixed with label, or an empty string if it holds.
+// regex is the compiled pattern of an =~ assertion; if nil, expected is compiled.
+func evaluateAssertion(label, op, expected string, regex *regexp.Regexp, actual string, found bool) string {
+	switch op {
+	case "exists":
+		if !found {
+			return fmt.Sprintf("%s: not found", label)
+		}
+		return ""
+	case "!exists":
+		if found {
+			return fmt.Sprintf("%s: found %s", label, actual)
+		}
+		return ""
+	}
+
+	if !found {
+		return fmt.Sprintf("%s: not found", label)
+	}
+
+	ok := false
+
+	switch op {
+	case "==":
+		ok = actual == expected
+	case "!=":
+		ok = actual != expected
+	case "=~":
+		if regex == nil {
+			var err error
+			if regex, err = regexp.Compile(expected); err != nil {
+				return fmt.Sprintf("%s: invalid regex: %v", label, err)
+			}
+		}
+		ok = regex.MatchString(actual)
+	default:
+		number, err := strconv.ParseFloat(actual, 64)
+		if err != nil {
+			return fmt.Sprintf("%s: got non-numeric %q", label, actual)
+		}
+		want, _ := strconv.ParseFloat(expected, 64)
+		ok = compareNumbers(number, op, want)
+	}
+
+	if !ok {
+		return fmt.Sprintf("%s: got %q", label, actual)
+	}
+	return ""
+}
+
+// isNumericOp reports whether the operator compares numbers
+func isNumericOp(op string) bool {
+	return op == "<" || op == "<=" || op == ">" || op == ">="
+}
+
+func compareNumbers(actual float64, op string, expected float64) bool {
+	switch op {
+	case "<":
+		return actual < expected
+	case "<=":
+		return actual <= expected
+	case ">":
+		return actual > expected
+	case ">=":
+		return actual >= expected
+	}
+	return false
+}
+
+// expectedStatus returns the acceptable status codes for a check: ExpectedCodes if
+// set, otherwise ExpectedCode, otherwise any 2xx
+func expectedStatus(params CheckParams) (StatusCodes, error) {
+	switch {
+	case params.ExpectedCodes != "":
+		return ParseStatusCodes(params.ExpectedCodes)
+	case params.ExpectedCode != 0:
+		return StatusCodes{{params.ExpectedCode, params.ExpectedCode}}, nil
+	default:
+		return StatusCodes{{200, 299}}, nil
+	}
+}
+
+// compiledAssertions are the parsed status codes, body regex and header and JSON
+// assertions of a check
+type compiledAssertions struct {
+	codes     StatusCodes
+	bodyRegex *regexp.Regexp
+	headers   []HeaderAssertion
+	json      []JSONAssertion
+	regexes   map[string]*regexp.Regexp // the compiled patterns of =~ assertions
+}
+
+func compileAssertions(params CheckParams) (*compiledAssertions, error) {
+	codes, err := expectedStatus(params)
+	if err != nil {
+		return nil, err
+	}
+	c := &compiledAssertions{codes: codes, regexes: make(map[string]*regexp.Regexp)}
+
+	if params.BodyRegex != "" {
+		if c.bodyRegex, err = regexp.Compile(params.BodyRegex); err != nil {
+			return nil, fmt.Errorf("invalid body regex: %w", err)
+		}
+	}
+	for _, spec := range params.HeaderAssertions {
+		name, op, expected, regex, err := parseAssertion("header", spec)
+		if err != nil {
+			return nil, err
+		}
+		c.headers = append(c.headers, HeaderAssertion{Name: name, Op: op, Expected: expected})
+		c.addRegex(expected, regex)
+	}
+	for _, spec := range params.JSONAssertions {
+		path, op, expected, regex, err := parseAssertion("JSON", spec)
+		if err != nil {
+			return nil, err
+		}
+		c.json = append(c.json, JSONAssertion{Path: path, Op: op, Expected: expected})
+		c.addRegex(expected, regex)
+	}
+
+	return c, nil
+}
+
+func (c *compiledAssertions) addRegex(pattern string, regex *regexp.Regexp) {
+	if regex != nil {
+		c.regexes[pattern] = regex
+	}
+}
+
+// ValidateAssertions checks that the status codes, body regex and header and JSON
+// assertions of a check are valid before any request is made, and keeps them
+// compiled in params for its checks
+func ValidateAssertions(params *CheckParams) error {
+	compiled, err := compileAssertions(*params)
+	if err != nil {
+		return err
+	}
+	params.compiled = compiled
+	return nil
+}
+
+// assertions returns the check's compiled assertions, compiling them if they
+// weren't validated
+func (p CheckParams) assertions() (*compiledAssertions, error) {
+	if p.compiled != nil {
+		return p.compiled, nil
+	}
+	return compileAssertions(p)
+}
+
+// needsBody reports whether any of the check's assertions require the response body
+func needsBody(params CheckParams) bool {
+	return params.ExpectedBody != "" || params.BodyRegex != "" || len(params.BodyNotContains) > 0 ||
+		len(params.JSONAssertions) > 0 || params.JSONValuePath != ""
+}
+
+// checkBody applies the regex and "must not contain" body checks and returns the failures
+func checkBody(body *bodyScanner, params CheckParams, assertions *compiledAssertions) []string {
+	var failures []string
+
+	if assertions.bodyRegex != nil && !assertions.bodyRegex.Match(body.buf.Bytes()) {
+		failures = append(failures, fmt.Sprintf("body does not match regex: %q", params.BodyRegex))
+	}
+	for _, m := range body.forbidden {
+		if m.found {
+			failures = append(failures, fmt.Sprintf("body contains forbidden string: %q", m.pattern))
+		}
+	}
+
+	return failures
+}
+
+// HeaderAssertion is a single assertion against a response header
+type HeaderAssertion struct {
+	Name     string
+	Op       string // ==, !=, =~, <, <=, >, >=, exists or !exists
+	Expected string
+}
+
+// ParseHeaderAssertion parses an assertion such as "Content-Type =~ ^application/json",
+// "Cache-Control == no-store", "Strict-Transport-Security exists" or "X-Powered-By !exists"
+func ParseHeaderAssertion(spec string) (HeaderAssertion, error) {
+	name, op, expected, _, err := parseAssertion("header", spec)
+	if err != nil {
+		return HeaderAssertion{}, err
+	}
+	return HeaderAssertion{Name: name, Op: op, Expected: expected}, nil
+}
+
+// String returns the assertion in its parseable form
+func (a HeaderAssertion) String() string {
+	return formatAssertion(a.Name, a.Op, a.Expected)
+}
+
+// Evaluate checks the assertion against the response headers and returns a
+// description of the failure, or an empty string if the assertion holds.
+// Repeated headers are joined with ", ".
+func (a HeaderAssertion) Evaluate(header http.Header) string {
+	return a.evaluate(header, nil)
+}
+
+func (a HeaderAssertion) evaluate(header http.Header, regex *regexp.Regexp) string {
+	values := header.Values(a.Name)
+	return evaluateAssertion("header "+a.String(), a.Op, a.Expected, regex, strings.Join(values, ", "), len(values) > 0)
+}
+
+// statusRange is an inclusive range of acceptable status codes
+type statusRange struct {
+	min, max int
+}
+
+// StatusCodes is a set of acceptable HTTP status codes
+type StatusCodes []statusRange
+
+// ParseStatusCodes parses a comma-separated list of status codes, classes and
+// ranges, e.g. "200,204,3xx" or "200-299,404"
+func ParseStatusCodes(spec string) (StatusCodes, error) {
+	var codes StatusCodes
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+
+		if len(part) == 3 && strings.HasSuffix(part, "xx") && part[0] >= '1' && part[0] <= '5' {
+			class := int(part[0]-'0') * 100
+			codes = append(codes, statusRange{class, class + 99})
+			continue
+		}
+
+		from, to, isRange := strings.Cut(part, "-")
+		min, err := parseStatusCode(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status code %q in %q", part, spec)
+		}
+		max := min
+		if isRange {
+			if max, err = parseStatusCode(to); err != nil || max < min {
+				return nil, fmt.Errorf("invalid status code range %q in %q", part, spec)
+			}
+		}
+		codes = append(codes, statusRange{min, max})
+	}
+
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("no status codes in %q", spec)
+	}
+	return codes, nil
+}
+
+func parseStatusCode(s string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || code < 100 || code > 599 {
+		return 0, fmt.Errorf("invalid status code: %s", s)
+	}
+	return code, nil
+}
+
+// Matches reports whether code is one of the acceptable status codes
+func (c StatusCodes) Matches(code int) bool {
+	for _, r := range c {
+		if code >= r.min && code <= r.max {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the status codes in their parseable form
+func (c StatusCodes) String() string {
+	parts := make([]string, len(c))
+	for i, r := range c {
+		switch {
+		case r.min == r.max:
+			parts[i] = strconv.Itoa(r.min)
+		case r.min%100 == 0 && r.max == r.min+99:
+			parts[i] = fmt.Sprintf("%dxx", r.min/100)
+		default:
+			parts[i] = fmt.Sprintf("%d-%d", r.min, r.max)
+		}
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,189 @@
+package urlcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseStatusCodes(t *testing.T) {
+	tests := []struct {
+		spec    string
+		match   []int
+		noMatch []int
+		wantErr bool
+	}{
+		{spec: "200", match: []int{200}, noMatch: []int{201, 404}},
+		{spec: "200,204,3xx", match: []int{200, 204, 301, 399}, noMatch: []int{201, 400}},
+		{spec: "200-299, 404", match: []int{200, 250, 404}, noMatch: []int{300, 500}},
+		{spec: "6xx", wantErr: true},
+		{spec: "299-200", wantErr: true},
+		{spec: "abc", wantErr: true},
+		{spec: ",", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			codes, err := ParseStatusCodes(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseStatusCodes(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			for _, code := range tt.match {
+				if !codes.Matches(code) {
+					t.Errorf("expected %q to match %d", tt.spec, code)
+				}
+			}
+			for _, code := range tt.noMatch {
+				if codes.Matches(code) {
+					t.Errorf("expected %q not to match %d", tt.spec, code)
+				}
+			}
+		})
+	}
+
+	codes, _ := ParseStatusCodes("200, 204,3XX,400-404")
+	if got := codes.String(); got != "200,204,3xx,400-404" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestHeaderAssertion(t *testing.T) {
+	header := http.Header{}
+	header.Set("Content-Type", "application/json; charset=utf-8")
+	header.Set("Cache-Control", "no-store")
+	header.Set("Content-Length", "512")
+
+	tests := []struct {
+		spec     string
+		wantFail bool
+	}{
+		{"Content-Type =~ ^application/json", false},
+		{"content-type =~ ^text/html", true},
+		{"Cache-Control == no-store", false},
+		{"Cache-Control != no-store", true},
+		{"Content-Length < 1024", false},
+		{"Strict-Transport-Security exists", true},
+		{"X-Powered-By !exists", false},
+		{"Cache-Control !exists", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			assertion, err := ParseHeaderAssertion(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseHeaderAssertion(%q) error = %v", tt.spec, err)
+			}
+			if failure := assertion.Evaluate(header); (failure != "") != tt.wantFail {
+				t.Errorf("Evaluate() = %q, wantFail %v", failure, tt.wantFail)
+			}
+		})
+	}
+}
+
+func TestValidateAssertions(t *testing.T) {
+	params := CheckParams{URL: "https://example.com", BodyRegex: `^OK`, HeaderAssertions: []string{"Server =~ ^nginx"}}
+	if err := ValidateAssertions(&params); err != nil {
+		t.Fatal(err)
+	}
+	if params.compiled == nil || params.compiled.bodyRegex == nil || params.compiled.regexes["^nginx"] == nil {
+		t.Errorf("expected the assertions to be kept compiled, got %+v", params.compiled)
+	}
+
+	params = CheckParams{URL: "https://example.com", BodyRegex: `(`}
+	if err := ValidateAssertions(&params); err == nil || !strings.Contains(err.Error(), "invalid body regex") {
+		t.Errorf("expected invalid body regex error, got %v", err)
+	}
+
+	// Unvalidated params report a bad pattern as an error rather than panicking
+	result := fetchURL(context.Background(), http.DefaultClient, params, fetchOptions{})
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "invalid body regex") {
+		t.Errorf("expected invalid body regex error, got %v", result.Error)
+	}
+}
+
+func TestCheck_ResponseAssertions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path == "/moved" {
+			w.Header().Set("Location", "/")
+			w.WriteHeader(http.StatusFound)
+			return
+		}
+		w.Write([]byte("<h1>Maintenance mode</h1><p>build 2024.10</p>"))
+	}))
+	defer server.Close()
+
+	cfg := Config{Dashboard: "test", Site: "test-site", Timeout: 5 * time.Second}
+
+	tests := []struct {
+		name        string
+		params      CheckParams
+		wantLevel   int
+		wantMessage []string
+	}{
+		{
+			name:      "status code list",
+			params:    CheckParams{URL: server.URL + "/moved", ExpectedCodes: "200,204,3xx"},
+			wantLevel: 0,
+		},
+		{
+			name:        "status code list mismatch",
+			params:      CheckParams{URL: server.URL, ExpectedCodes: "204,3xx"},
+			wantLevel:   2,
+			wantMessage: []string{"expected status 204,3xx"},
+		},
+		{
+			name:      "body regex",
+			params:    CheckParams{URL: server.URL, BodyRegex: `build \d{4}\.\d+`},
+			wantLevel: 0,
+		},
+		{
+			name:        "body regex mismatch",
+			params:      CheckParams{URL: server.URL, BodyRegex: `^OK$`},
+			wantLevel:   2,
+			wantMessage: []string{`body does not match regex: "^OK$"`},
+		},
+		{
+			name:        "forbidden strings",
+			params:      CheckParams{URL: server.URL, BodyNotContains: []string{"Maintenance mode", "Traceback"}},
+			wantLevel:   2,
+			wantMessage: []string{`body contains forbidden string: "Maintenance mode"`},
+		},
+		{
+			name: "header assertions",
+			params: CheckParams{URL: server.URL, HeaderAssertions: []string{
+				"Content-Type =~ ^text/html",
+				"Strict-Transport-Security exists",
+			}},
+			wantLevel:   2,
+			wantMessage: []string{"header Strict-Transport-Security exists: not found"},
+		},
+		{
+			name:        "invalid regex",
+			params:      CheckParams{URL: server.URL, BodyRegex: `(`},
+			wantLevel:   2,
+			wantMessage: []string{"invalid body regex"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Check(context.Background(), cfg, tt.params)
+
+			if result.AlertLevel != tt.wantLevel {
+				t.Errorf("expected AlertLevel %d, got %d (%s)", tt.wantLevel, result.AlertLevel, result.Message)
+			}
+			for _, want := range tt.wantMessage {
+				if !strings.Contains(result.Message, want) {
+					t.Errorf("expected message to contain %q, got %q", want, result.Message)
+				}
+			}
+			if strings.Contains(result.Message, "Traceback") {
+				t.Errorf("expected absent forbidden string to be omitted, got %q", result.Message)
+			}
+		})
+	}
+}
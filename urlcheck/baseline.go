@@ -0,0 +1,77 @@
+package urlcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/alertbingo/alertbingo/internal/atomicfile"
+)
+
+// Baseline persists the content hash last seen for each URL so that unexpected
+// content changes can be detected
+type Baseline struct {
+	path    string
+	mu      sync.Mutex
+	content map[string]seenContent
+}
+
+type seenContent struct {
+	SHA256 string    `json:"sha256"`
+	Size   int64     `json:"size"`
+	SeenAt time.Time `json:"seen_at"`
+}
+
+// LoadBaseline reads the baseline file at path. A missing file results in an empty baseline.
+func LoadBaseline(path string) (*Baseline, error) {
+	baseline := &Baseline{path: path, content: make(map[string]seenContent)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return baseline, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &baseline.content); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline file: %w", err)
+	}
+
+	return baseline, nil
+}
+
+// Save writes the baseline back to its file
+func (b *Baseline) Save() error {
+	b.mu.Lock()
+	data, err := json.MarshalIndent(b.content, "", "  ")
+	b.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal baseline: %w", err)
+	}
+
+	if err := atomicfile.Write(b.path, data); err != nil {
+		return fmt.Errorf("failed to write baseline file: %w", err)
+	}
+
+	return nil
+}
+
+// observe records the content hash for a URL and returns a description of the
+// change if different content was previously seen
+func (b *Baseline) observe(url, sha256 string, size int64) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	previous, seen := b.content[url]
+	b.content[url] = seenContent{SHA256: sha256, Size: size, SeenAt: time.Now().UTC()}
+
+	if !seen || previous.SHA256 == sha256 {
+		return ""
+	}
+
+	return fmt.Sprintf("content changed since %s: was sha256 %s (%d bytes), now %s (%d bytes)",
+		previous.SeenAt.Format(time.RFC3339), previous.SHA256, previous.Size, sha256, size)
+}
@@ -0,0 +1,140 @@
+package urlcheck
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// DefaultBodyLimit is the maximum number of response body bytes read when
+// Config.BodyLimit is not set
+const DefaultBodyLimit = 10 << 20
+
+// errBodyLimit stops reading a body once the limit has been reached
+var errBodyLimit = errors.New("body limit reached")
+
+// bodyScanner checks a response body as it is read, so that only the regex and
+// JSON checks need the body in memory. It reads at most limit bytes.
+type bodyScanner struct {
+	limit     int64
+	size      int64
+	truncated bool
+	hash      hash.Hash
+	buf       *bytes.Buffer // nil unless a check needs the whole body
+	contains  *streamMatcher
+	forbidden []*streamMatcher
+}
+
+func newBodyScanner(params CheckParams, limit int64, keepBody bool) *bodyScanner {
+	s := &bodyScanner{limit: limit, hash: sha256.New()}
+
+	if keepBody || params.BodyRegex != "" || len(params.JSONAssertions) > 0 || params.JSONValuePath != "" {
+		s.buf = new(bytes.Buffer)
+	}
+	if params.ExpectedBody != "" {
+		s.contains = newStreamMatcher(params.ExpectedBody)
+	}
+	for _, str := range params.BodyNotContains {
+		s.forbidden = append(s.forbidden, newStreamMatcher(str))
+	}
+
+	return s
+}
+
+// scan reads the body into the scanner, stopping at the limit
+func (s *bodyScanner) scan(body io.Reader) error {
+	_, err := io.Copy(s, body)
+	if errors.Is(err, errBodyLimit) {
+		return nil
+	}
+	return err
+}
+
+// Write implements io.Writer, returning errBodyLimit once the limit is reached
+func (s *bodyScanner) Write(p []byte) (int, error) {
+	var err error
+	if remaining := s.limit - s.size; int64(len(p)) > remaining {
+		p = p[:remaining]
+		s.truncated = true
+		err = errBodyLimit
+	}
+
+	s.size += int64(len(p))
+	s.hash.Write(p)
+	if s.buf != nil {
+		s.buf.Write(p)
+	}
+	if s.contains != nil {
+		s.contains.write(p)
+	}
+	for _, m := range s.forbidden {
+		m.write(p)
+	}
+
+	return len(p), err
+}
+
+// sum returns the hex SHA-256 of the body read so far
+func (s *bodyScanner) sum() string {
+	return hex.EncodeToString(s.hash.Sum(nil))
+}
+
+// streamMatcher finds a string in a stream of chunks, keeping only enough of the
+// previous chunk to match across chunk boundaries
+type streamMatcher struct {
+	pattern []byte
+	tail    []byte
+	found   bool
+}
+
+func newStreamMatcher(pattern string) *streamMatcher {
+	return &streamMatcher{pattern: []byte(pattern)}
+}
+
+func (m *streamMatcher) write(p []byte) {
+	if m.found {
+		return
+	}
+
+	data := append(m.tail, p...)
+	if bytes.Contains(data, m.pattern) {
+		m.found = true
+		m.tail = nil
+		return
+	}
+
+	if keep := len(m.pattern) - 1; len(data) > keep {
+		data = data[len(data)-keep:]
+	}
+	m.tail = append(m.tail[:0], data...)
+}
+
+// bodySizeReasons returns the alert reasons for the body size and hash checks
+func bodySizeReasons(params CheckParams, result Result, limit int64) []string {
+	var reasons []string
+
+	size := fmt.Sprintf("%d bytes", result.BodySize)
+	if result.BodyTruncated {
+		size = fmt.Sprintf("more than %d bytes", result.BodySize)
+		if needsBody(params) || params.ExpectedSHA256 != "" || params.MaxBodyBytes >= limit {
+			reasons = append(reasons, fmt.Sprintf("body exceeds read limit of %d bytes", limit))
+		}
+	}
+
+	if params.MinBodyBytes > 0 && result.BodySize < params.MinBodyBytes {
+		reasons = append(reasons, fmt.Sprintf("body size %s is below min %d bytes", size, params.MinBodyBytes))
+	}
+	if params.MaxBodyBytes > 0 && result.BodySize > params.MaxBodyBytes {
+		reasons = append(reasons, fmt.Sprintf("body size %s exceeds max %d bytes", size, params.MaxBodyBytes))
+	}
+	if params.ExpectedSHA256 != "" && !result.BodyTruncated && !strings.EqualFold(result.ContentHash, params.ExpectedSHA256) {
+		reasons = append(reasons, fmt.Sprintf("content sha256 %s, expected %s", result.ContentHash, params.ExpectedSHA256))
+	}
+
+	return reasons
+}
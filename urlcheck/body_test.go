@@ -0,0 +1,173 @@
+package urlcheck
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamMatcher(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   bool
+	}{
+		{"single chunk", []string{"<p>Maintenance mode</p>"}, true},
+		{"across chunks", []string{"<p>Mainten", "ance ", "mode</p>"}, true},
+		{"byte by byte", strings.Split("xxMaintenance modexx", ""), true},
+		{"absent", []string{"<p>Maintenance", "</p><p>mode</p>"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newStreamMatcher("Maintenance mode")
+			for _, chunk := range tt.chunks {
+				m.write([]byte(chunk))
+			}
+			if m.found != tt.want {
+				t.Errorf("found = %v, want %v", m.found, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck_BodyLimitAndSize(t *testing.T) {
+	page := strings.Repeat("a", 4096) + "needle"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	sum := sha256.Sum256([]byte(page))
+	pageHash := hex.EncodeToString(sum[:])
+
+	cfg := Config{Dashboard: "test", Site: "test-site", Timeout: 5 * time.Second}
+
+	tests := []struct {
+		name        string
+		bodyLimit   int64
+		params      CheckParams
+		wantLevel   int
+		wantMessage string
+	}{
+		{
+			name:      "within limit",
+			params:    CheckParams{URL: server.URL, ExpectedBody: "needle", MinBodyBytes: 1000, MaxBodyBytes: 5000},
+			wantLevel: 0,
+		},
+		{
+			name:        "over limit with body check",
+			bodyLimit:   1024,
+			params:      CheckParams{URL: server.URL, ExpectedBody: "needle"},
+			wantLevel:   2,
+			wantMessage: "body exceeds read limit of 1024 bytes",
+		},
+		{
+			name:      "over limit without body check",
+			bodyLimit: 1024,
+			params:    CheckParams{URL: server.URL},
+			wantLevel: 0,
+		},
+		{
+			name:        "too small",
+			params:      CheckParams{URL: server.URL, MinBodyBytes: 10000},
+			wantLevel:   2,
+			wantMessage: "body size 4102 bytes is below min 10000 bytes",
+		},
+		{
+			name:        "too large",
+			bodyLimit:   2048,
+			params:      CheckParams{URL: server.URL, MaxBodyBytes: 1024},
+			wantLevel:   2,
+			wantMessage: "body size more than 2048 bytes exceeds max 1024 bytes",
+		},
+		{
+			name:      "expected hash",
+			params:    CheckParams{URL: server.URL, ExpectedSHA256: strings.ToUpper(pageHash)},
+			wantLevel: 0,
+		},
+		{
+			name:        "unexpected hash",
+			params:      CheckParams{URL: server.URL, ExpectedSHA256: strings.Repeat("0", 64)},
+			wantLevel:   2,
+			wantMessage: "content sha256 " + pageHash,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := cfg
+			cfg.BodyLimit = tt.bodyLimit
+
+			result := Check(context.Background(), cfg, tt.params)
+
+			if result.AlertLevel != tt.wantLevel {
+				t.Errorf("expected AlertLevel %d, got %d (%s)", tt.wantLevel, result.AlertLevel, result.Message)
+			}
+			if !strings.Contains(result.Message, tt.wantMessage) {
+				t.Errorf("expected message to contain %q, got %q", tt.wantMessage, result.Message)
+			}
+		})
+	}
+}
+
+func TestCheck_Baseline(t *testing.T) {
+	content := "version 1"
+	status := http.StatusOK
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	baseline, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Config{Dashboard: "test", Site: "test-site", Timeout: 5 * time.Second, Baseline: baseline}
+	params := CheckParams{URL: server.URL}
+
+	// The first check records the baseline
+	if result := Check(context.Background(), cfg, params); result.AlertLevel != 0 {
+		t.Fatalf("expected first check to be OK, got %d (%s)", result.AlertLevel, result.Message)
+	}
+	if err := baseline.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A saved baseline is used by later runs
+	cfg.Baseline, err = LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Error pages don't replace the baseline
+	content, status = "oops", http.StatusInternalServerError
+	Check(context.Background(), cfg, params)
+
+	content, status = "version 1", http.StatusOK
+	if result := Check(context.Background(), cfg, params); result.AlertLevel != 0 {
+		t.Errorf("expected unchanged content to be OK, got %d (%s)", result.AlertLevel, result.Message)
+	}
+
+	content = "version 2"
+	result := Check(context.Background(), cfg, params)
+	if result.AlertLevel != 1 || !strings.Contains(result.Message, "content changed since") {
+		t.Errorf("expected content change warning, got %d (%s)", result.AlertLevel, result.Message)
+	}
+
+	// The new content becomes the baseline
+	if result := Check(context.Background(), cfg, params); result.AlertLevel != 0 {
+		t.Errorf("expected OK after baseline update, got %d (%s)", result.AlertLevel, result.Message)
+	}
+}
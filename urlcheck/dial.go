@@ -0,0 +1,140 @@
+package urlcheck
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/alertbingo/alertbingo/internal/checkutil"
+)
+
+// Resolve overrides the address connected to for a host and port, like curl's
+// --resolve. The request's Host header, SNI and certificate verification still
+// use the hostname. An empty Host or Port matches any.
+type Resolve struct {
+	Host    string
+	Port    string
+	Address string
+}
+
+// ParseResolve parses a host:port:address specification, as certcheck.ParseConnectTo
+// does. The address is an IP address or hostname; IPv6 addresses may be given in
+// brackets (e.g. example.com:443:[2001:db8::1]).
+func ParseResolve(spec string) (Resolve, error) {
+	host, port, address, err := checkutil.ParseHostOverride(spec)
+	if err != nil {
+		return Resolve{}, fmt.Errorf("invalid resolve: %s (%w)", spec, err)
+	}
+	return Resolve{Host: host, Port: port, Address: address}, nil
+}
+
+// matches reports whether the override applies to the given host and port
+func (r Resolve) matches(host, port string) bool {
+	return (r.Host == "" || strings.EqualFold(r.Host, host)) && (r.Port == "" || r.Port == port)
+}
+
+// resolveOverride returns the overridden address for host and port, if any
+func resolveOverride(overrides []Resolve, host, port string) (string, bool) {
+	for _, r := range overrides {
+		if r.matches(host, port) {
+			return r.Address, true
+		}
+	}
+	return "", false
+}
+
+// urlPort returns the port of a URL, defaulting to the scheme's
+func urlPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	if u.Scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
+
+// directForResolved wraps a proxy function so that requests to hosts with a
+// resolve override bypass the proxy. Through a proxy, the override would apply
+// to the connection to the proxy rather than to the host.
+func directForResolved(proxy func(*http.Request) (*url.URL, error), overrides []Resolve) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		if _, ok := resolveOverride(overrides, req.URL.Hostname(), urlPort(req.URL)); ok || proxy == nil {
+			return nil, nil
+		}
+		return proxy(req)
+	}
+}
+
+// needsDialer reports whether the config requires a custom dialer
+func (cfg Config) needsDialer() bool {
+	return cfg.Network != "" || len(cfg.Resolve) > 0
+}
+
+// dialContext returns a DialContext function that applies the config's network
+// and resolve overrides
+func (cfg Config) dialContext() func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if cfg.Network != "" {
+			network = cfg.Network
+		}
+		if host, port, err := net.SplitHostPort(addr); err == nil {
+			if address, ok := resolveOverride(cfg.Resolve, host, port); ok {
+				addr = net.JoinHostPort(address, port)
+			}
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
+
+// resolveAddresses returns the addresses a URL's host resolves to, restricted to
+// the config's network. A resolve override or IP address host is used as is.
+func resolveAddresses(ctx context.Context, cfg Config, rawURL string) ([]string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid URL: %s", rawURL)
+	}
+
+	host, port := u.Hostname(), urlPort(u)
+	if address, ok := resolveOverride(cfg.Resolve, host, port); ok {
+		return []string{address}, nil
+	}
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+
+	network := "ip"
+	switch cfg.Network {
+	case "tcp4":
+		network = "ip4"
+	case "tcp6":
+		network = "ip6"
+	}
+
+	ips, err := net.DefaultResolver.LookupIP(ctx, network, host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+
+	addresses := make([]string, len(ips))
+	for i, ip := range ips {
+		addresses[i] = ip.String()
+	}
+	return addresses, nil
+}
+
+// pinAddress returns a copy of the config that connects to address for the URL's host
+func pinAddress(cfg Config, rawURL, address string) Config {
+	if u, err := url.Parse(rawURL); err == nil {
+		// Prepend so the pin takes precedence over existing overrides
+		cfg.Resolve = append([]Resolve{{Host: u.Hostname(), Address: address}}, cfg.Resolve...)
+	}
+	cfg.address = address
+	return cfg
+}
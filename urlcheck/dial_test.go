@@ -0,0 +1,223 @@
+package urlcheck
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseResolve(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Resolve
+		wantErr bool
+	}{
+		{"example.com:443:203.0.113.10", Resolve{"example.com", "443", "203.0.113.10"}, false},
+		{"example.com:443:[2001:db8::1]", Resolve{"example.com", "443", "2001:db8::1"}, false},
+		{"example.com::2001:db8::1", Resolve{"example.com", "", "2001:db8::1"}, false},
+		{"example.com:443:backend.internal", Resolve{"example.com", "443", "backend.internal"}, false},
+		{":443:203.0.113.10", Resolve{"", "443", "203.0.113.10"}, false},
+		{"example.com:443", Resolve{}, true},
+		{"example.com:443:", Resolve{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseResolve(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseResolve(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseResolve(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck_Resolve(t *testing.T) {
+	var gotHost string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost = r.Host
+	}))
+	defer server.Close()
+
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	url := "http://app.example.test:" + port + "/health"
+
+	cfg := Config{
+		Dashboard: "test",
+		Site:      "test-site",
+		Timeout:   5 * time.Second,
+		Resolve:   []Resolve{{Host: "APP.example.test", Port: port, Address: "127.0.0.1"}},
+	}
+
+	result := Check(context.Background(), cfg, CheckParams{URL: url})
+	if result.AlertLevel != 0 {
+		t.Fatalf("expected AlertLevel 0, got %d (%s)", result.AlertLevel, result.Message)
+	}
+	if gotHost != "app.example.test:"+port {
+		t.Errorf("expected the original Host header, got %q", gotHost)
+	}
+
+	// The IPv4 server can't be reached over IPv6
+	cfg.Network = "tcp6"
+	result = Check(context.Background(), cfg, CheckParams{URL: url})
+	if result.AlertLevel != 2 || result.Value != "Error" {
+		t.Errorf("expected error over IPv6, got level %d value %q (%s)", result.AlertLevel, result.Value, result.Message)
+	}
+}
+
+func TestResolveAddresses(t *testing.T) {
+	cfg := Config{Resolve: []Resolve{{Host: "example.com", Port: "443", Address: "203.0.113.10"}}}
+
+	tests := []struct {
+		url  string
+		want []string
+	}{
+		{"https://example.com/", []string{"203.0.113.10"}},
+		{"http://192.0.2.1:8080/", []string{"192.0.2.1"}},
+		{"http://[2001:db8::1]/", []string{"2001:db8::1"}},
+	}
+
+	for _, tt := range tests {
+		got, err := resolveAddresses(context.Background(), cfg, tt.url)
+		if err != nil {
+			t.Fatalf("resolveAddresses(%q) error = %v", tt.url, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("resolveAddresses(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestCheckAll_EachAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	cfg := Config{Dashboard: "test", Site: "test-site", Timeout: 5 * time.Second, Network: "tcp4", EachAddress: true}
+
+	targets := []CheckParams{
+		{URL: "http://localhost:" + port + "/"},
+		{URL: "http://host.invalid/"},
+	}
+
+	checks := CheckAll(context.Background(), cfg, targets, 2)
+	if len(checks) != 2 {
+		t.Fatalf("expected 2 checks, got %d", len(checks))
+	}
+	if checks[0].Service != targets[0].URL+" (127.0.0.1)" || checks[0].AlertLevel != 0 {
+		t.Errorf("expected OK check for 127.0.0.1, got %q level %d (%s)", checks[0].Service, checks[0].AlertLevel, checks[0].Message)
+	}
+	if checks[1].AlertLevel != 2 || !strings.Contains(checks[1].Message, "failed to resolve host.invalid") {
+		t.Errorf("expected resolution failure, got level %d (%s)", checks[1].AlertLevel, checks[1].Message)
+	}
+}
+
+func TestCheck_Proxy(t *testing.T) {
+	var gotURL, gotAuth string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURL, gotAuth = r.URL.String(), r.Header.Get("Proxy-Authorization")
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	proxyURL.User = url.UserPassword("monitor", "s3cret")
+	cfg := Config{Dashboard: "test", Site: "test-site", Timeout: 5 * time.Second, Proxy: http.ProxyURL(proxyURL)}
+
+	result := Check(context.Background(), cfg, CheckParams{URL: "http://app.example.test/health"})
+	if result.AlertLevel != 0 {
+		t.Fatalf("expected AlertLevel 0, got %d (%s)", result.AlertLevel, result.Message)
+	}
+	if gotURL != "http://app.example.test/health" {
+		t.Errorf("expected the proxy to receive the absolute URL, got %q", gotURL)
+	}
+	if gotAuth != "Basic bW9uaXRvcjpzM2NyZXQ=" {
+		t.Errorf("expected Proxy-Authorization header, got %q", gotAuth)
+	}
+}
+
+func TestCheck_ProxyBypassedForResolve(t *testing.T) {
+	proxied := false
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = true
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer proxy.Close()
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer target.Close()
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(target.URL, "http://"))
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	cfg := Config{
+		Dashboard: "test",
+		Site:      "test-site",
+		Timeout:   5 * time.Second,
+		Proxy:     http.ProxyURL(proxyURL),
+		Resolve:   []Resolve{{Host: "app.example.test", Port: port, Address: "127.0.0.1"}},
+	}
+
+	result := Check(context.Background(), cfg, CheckParams{URL: "http://app.example.test:" + port + "/health"})
+	if result.AlertLevel != 0 {
+		t.Errorf("expected AlertLevel 0, got %d (%s)", result.AlertLevel, result.Message)
+	}
+	if proxied {
+		t.Error("expected the resolved host to be checked directly, not through the proxy")
+	}
+
+	// Hosts without an override still use the proxy
+	Check(context.Background(), cfg, CheckParams{URL: "http://other.example.test/health"})
+	if !proxied {
+		t.Error("expected other hosts to use the proxy")
+	}
+}
+
+func TestCheck_BaselinePerAddress(t *testing.T) {
+	// Listen on every loopback address and serve content that differs by address
+	listener, err := net.Listen("tcp4", "0.0.0.0:0")
+	if err != nil {
+		t.Skipf("cannot listen on all addresses: %v", err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Context().Value(http.LocalAddrContextKey).(net.Addr).String()))
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	if conn, err := net.Dial("tcp4", "127.0.0.2:"+port); err != nil {
+		t.Skipf("127.0.0.2 not reachable: %v", err)
+	} else {
+		conn.Close()
+	}
+
+	baseline, err := LoadBaseline(filepath.Join(t.TempDir(), "baseline.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{Dashboard: "test", Site: "test-site", Timeout: 5 * time.Second, Baseline: baseline}
+	params := CheckParams{URL: "http://app.example.test:" + port + "/"}
+
+	for run := 1; run <= 2; run++ {
+		for _, address := range []string{"127.0.0.1", "127.0.0.2"} {
+			check := Check(context.Background(), pinAddress(cfg, params.URL, address), params)
+			if check.AlertLevel != 0 {
+				t.Errorf("run %d: expected %s to be OK, got level %d (%s)", run, address, check.AlertLevel, check.Message)
+			}
+			if want := params.URL + " (" + address + ")"; check.Service != want {
+				t.Errorf("expected service %q, got %q", want, check.Service)
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package urlcheck
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// JSONAssertion is a single assertion against a value in a JSON response body
+type JSONAssertion struct {
+	Path     string // e.g. $.db, queue.depth or items[0].name
+	Op       string // ==, !=, =~, <, <=, >, >=, exists or !exists
+	Expected string
+}
+
+// ParseJSONAssertion parses an assertion such as "db == ok", "queue_depth < 1000",
+// "version =~ ^2\." or "error !exists"
+func ParseJSONAssertion(spec string) (JSONAssertion, error) {
+	path, op, expected, _, err := parseAssertion("JSON", spec)
+	if err != nil {
+		return JSONAssertion{}, err
+	}
+	return JSONAssertion{Path: path, Op: op, Expected: expected}, nil
+}
+
+// String returns the assertion in its parseable form
+func (a JSONAssertion) String() string {
+	return formatAssertion(a.Path, a.Op, a.Expected)
+}
+
+// Evaluate checks the assertion against a decoded JSON document and returns a
+// description of the failure, or an empty string if the assertion holds
+func (a JSONAssertion) Evaluate(doc any) string {
+	return a.evaluate(doc, nil)
+}
+
+func (a JSONAssertion) evaluate(doc any, regex *regexp.Regexp) string {
+	value, found := lookupJSON(doc, a.Path)
+	var actual string
+	if found {
+		actual = formatJSONValue(value)
+	}
+	return evaluateAssertion(a.String(), a.Op, a.Expected, regex, actual, found)
+}
+
+// lookupJSON resolves a dotted path with optional array indexes (e.g. $.items[0].name)
+// in a decoded JSON document
+func lookupJSON(doc any, path string) (any, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return doc, true
+	}
+
+	current := doc
+	for _, segment := range strings.Split(path, ".") {
+		key, indexes, err := splitIndexes(segment)
+		if err != nil {
+			return nil, false
+		}
+
+		if key != "" {
+			obj, ok := current.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if current, ok = obj[key]; !ok {
+				return nil, false
+			}
+		}
+
+		for _, index := range indexes {
+			arr, ok := current.([]any)
+			if !ok || index < 0 || index >= len(arr) {
+				return nil, false
+			}
+			current = arr[index]
+		}
+	}
+
+	return current, true
+}
+
+// splitIndexes splits a path segment like "items[0][1]" into its key and indexes
+func splitIndexes(segment string) (string, []int, error) {
+	key, rest, _ := strings.Cut(segment, "[")
+	if rest == "" {
+		return key, nil, nil
+	}
+
+	var indexes []int
+	for _, part := range strings.Split("["+rest, "[")[1:] {
+		n, err := strconv.Atoi(strings.TrimSuffix(part, "]"))
+		if err != nil || !strings.HasSuffix(part, "]") {
+			return "", nil, fmt.Errorf("invalid index in %q", segment)
+		}
+		indexes = append(indexes, n)
+	}
+	return key, indexes, nil
+}
+
+// formatJSONValue formats a decoded JSON value for comparison and display
+func formatJSONValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+// evaluateJSON decodes the body and evaluates the JSON assertions, if any, returning
+// the failures and the formatted value at valuePath (if set and found). A body that
+// isn't valid JSON is reported as a failure.
+func evaluateJSON(body []byte, assertions *compiledAssertions, valuePath string) ([]string, string) {
+	// Decode numbers as json.Number so large integers keep their precision
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return []string{fmt.Sprintf("response is not valid JSON: %v", err)}, ""
+	}
+
+	var failures []string
+	if assertions != nil {
+		for _, assertion := range assertions.json {
+			if failure := assertion.evaluate(doc, assertions.regexes[assertion.Expected]); failure != "" {
+				failures = append(failures, failure)
+			}
+		}
+	}
+
+	var value string
+	if valuePath != "" {
+		if v, found := lookupJSON(doc, valuePath); found {
+			value = formatJSONValue(v)
+		}
+	}
+
+	return failures, value
+}
@@ -0,0 +1,116 @@
+package urlcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseJSONAssertion(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    JSONAssertion
+		wantErr bool
+	}{
+		{"db == ok", JSONAssertion{"db", "==", "ok"}, false},
+		{"$.queue.depth<=1000", JSONAssertion{"$.queue.depth", "<=", "1000"}, false},
+		{"version =~ ^2\\.", JSONAssertion{"version", "=~", "^2\\."}, false},
+		{"status != down", JSONAssertion{"status", "!=", "down"}, false},
+		{"items[0].name exists", JSONAssertion{"items[0].name", "exists", ""}, false},
+		{"error !exists", JSONAssertion{"error", "!exists", ""}, false},
+		{"depth < many", JSONAssertion{}, true},
+		{"version =~ (", JSONAssertion{}, true},
+		{"just-a-path", JSONAssertion{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseJSONAssertion(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseJSONAssertion(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseJSONAssertion(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateJSON(t *testing.T) {
+	body := []byte(`{"db":"ok","queue_depth":12,"healthy":true,"id":12345678901234567,"items":[{"name":"a"}],"error":null}`)
+
+	tests := []struct {
+		assertion string
+		wantFail  bool
+	}{
+		{"db == ok", false},
+		{"db != ok", true},
+		{"queue_depth < 1000", false},
+		{"queue_depth >= 13", true},
+		{"healthy == true", false},
+		{"id == 12345678901234567", false},
+		{"items[0].name == a", false},
+		{"items[1].name exists", true},
+		{"error exists", false},
+		{"error == null", false},
+		{"missing !exists", false},
+		{"db =~ ^o", false},
+		{"db < 5", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.assertion, func(t *testing.T) {
+			assertions, err := compileAssertions(CheckParams{JSONAssertions: []string{tt.assertion}})
+			if err != nil {
+				t.Fatalf("compileAssertions() error = %v", err)
+			}
+			failures, _ := evaluateJSON(body, assertions, "")
+			if (len(failures) > 0) != tt.wantFail {
+				t.Errorf("assertion %q failures = %v, wantFail %v", tt.assertion, failures, tt.wantFail)
+			}
+		})
+	}
+
+	_, value := evaluateJSON(body, nil, "$.queue_depth")
+	if value != "12" {
+		t.Errorf("expected value '12', got %q", value)
+	}
+
+	failures, _ := evaluateJSON([]byte("<html>"), nil, "")
+	if len(failures) != 1 || !strings.Contains(failures[0], "not valid JSON") {
+		t.Errorf("expected invalid JSON failure, got %v", failures)
+	}
+}
+
+func TestCheck_JSONAssertions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"db":"ok","queue_depth":1500}`))
+	}))
+	defer server.Close()
+
+	cfg := Config{Dashboard: "test", Site: "test-site", Timeout: 5 * time.Second}
+	params := CheckParams{
+		URL:            server.URL,
+		JSONAssertions: []string{"db == ok", "queue_depth < 1000", "cache == ok"},
+		JSONValuePath:  "queue_depth",
+	}
+
+	result := Check(context.Background(), cfg, params)
+
+	if result.AlertLevel != 2 {
+		t.Errorf("expected AlertLevel 2, got %d", result.AlertLevel)
+	}
+	if result.Value != "1500" {
+		t.Errorf("expected Value '1500', got %q", result.Value)
+	}
+	if !strings.Contains(result.Message, `queue_depth < 1000: got "1500"`) || !strings.Contains(result.Message, "cache == ok: not found") {
+		t.Errorf("expected each failing assertion in message, got %q", result.Message)
+	}
+	if strings.Contains(result.Message, "db == ok") {
+		t.Errorf("expected passing assertion to be omitted, got %q", result.Message)
+	}
+}
@@ -0,0 +1,79 @@
+package urlcheck
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// DefaultMaxRedirects is the maximum number of redirects followed when
+// CheckParams.MaxRedirects is not set
+const DefaultMaxRedirects = 10
+
+// redirectPolicy follows redirects for a single check, recording the chain and
+// stopping at the first loop, unexpected host or hop beyond the limit
+type redirectPolicy struct {
+	maxRedirects int
+	allowedHosts []string // hosts redirects may go to besides the original host; empty allows any
+	chain        []string // every URL requested, starting with the original
+	violation    string   // why redirects were stopped, if they were
+}
+
+func newRedirectPolicy(params CheckParams) *redirectPolicy {
+	maxRedirects := params.MaxRedirects
+	if maxRedirects == 0 {
+		maxRedirects = DefaultMaxRedirects
+	}
+	return &redirectPolicy{
+		maxRedirects: maxRedirects,
+		allowedHosts: params.AllowedRedirectHosts,
+		chain:        []string{params.URL},
+	}
+}
+
+// checkRedirect is used as http.Client.CheckRedirect. Rather than failing the
+// request, a violation stops at the current response so that it can be reported.
+func (p *redirectPolicy) checkRedirect(req *http.Request, via []*http.Request) error {
+	next := req.URL.String()
+
+	for _, visited := range p.chain {
+		if visited == next {
+			p.violation = fmt.Sprintf("redirect loop: %s", strings.Join(append(p.chain, next), " -> "))
+			return http.ErrUseLastResponse
+		}
+	}
+	if len(via) > p.maxRedirects {
+		p.violation = fmt.Sprintf("too many redirects (max %d)", p.maxRedirects)
+		return http.ErrUseLastResponse
+	}
+	if !p.hostAllowed(req.URL.Hostname(), via[0].URL.Hostname()) {
+		p.violation = fmt.Sprintf("redirect to unexpected host: %s", next)
+		return http.ErrUseLastResponse
+	}
+
+	p.chain = append(p.chain, next)
+	return nil
+}
+
+// hostAllowed reports whether a redirect may go to host. The original host is
+// always allowed; allowed hosts may use a leading wildcard such as *.example.com.
+func (p *redirectPolicy) hostAllowed(host, original string) bool {
+	if len(p.allowedHosts) == 0 || strings.EqualFold(host, original) {
+		return true
+	}
+	for _, pattern := range p.allowedHosts {
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(host)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// redirects returns the redirect chain, or an empty string if there were no redirects
+func (p *redirectPolicy) redirects() string {
+	if len(p.chain) < 2 {
+		return ""
+	}
+	return "redirects: " + strings.Join(p.chain, " -> ")
+}
@@ -0,0 +1,97 @@
+package urlcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheck_FollowRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/old", http.RedirectHandler("/new", http.StatusMovedPermanently))
+	mux.Handle("/new", http.RedirectHandler("/home/", http.StatusFound))
+	mux.HandleFunc("/home/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("welcome"))
+	})
+	mux.Handle("/loop-a", http.RedirectHandler("/loop-b", http.StatusFound))
+	mux.Handle("/loop-b", http.RedirectHandler("/loop-a", http.StatusFound))
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// localhost is a different host from the server's 127.0.0.1
+	external := strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/home/"
+	mux.Handle("/external", http.RedirectHandler(external, http.StatusFound))
+
+	cfg := Config{Dashboard: "test", Site: "test-site", Timeout: 5 * time.Second}
+
+	tests := []struct {
+		name        string
+		params      CheckParams
+		wantLevel   int
+		wantMessage string
+	}{
+		{
+			name:        "not followed by default",
+			params:      CheckParams{URL: server.URL + "/old"},
+			wantLevel:   2,
+			wantMessage: "expected status 2xx",
+		},
+		{
+			name:        "followed",
+			params:      CheckParams{URL: server.URL + "/old", FollowRedirects: true, ExpectedBody: "welcome"},
+			wantLevel:   0,
+			wantMessage: "redirects: " + server.URL + "/old -> " + server.URL + "/new -> " + server.URL + "/home/",
+		},
+		{
+			name:      "expected final URL",
+			params:    CheckParams{URL: server.URL + "/old", FollowRedirects: true, ExpectedFinalURL: server.URL + "/home/"},
+			wantLevel: 0,
+		},
+		{
+			name:        "unexpected final URL",
+			params:      CheckParams{URL: server.URL + "/old", FollowRedirects: true, ExpectedFinalURL: "https://www.example.com/"},
+			wantLevel:   2,
+			wantMessage: "expected final URL https://www.example.com/, got " + server.URL + "/home/",
+		},
+		{
+			name:        "too many redirects",
+			params:      CheckParams{URL: server.URL + "/old", FollowRedirects: true, MaxRedirects: 1},
+			wantLevel:   2,
+			wantMessage: "too many redirects (max 1)",
+		},
+		{
+			name:        "loop",
+			params:      CheckParams{URL: server.URL + "/loop-a", FollowRedirects: true},
+			wantLevel:   2,
+			wantMessage: "redirect loop: " + server.URL + "/loop-a -> " + server.URL + "/loop-b -> " + server.URL + "/loop-a",
+		},
+		{
+			name:        "unexpected host",
+			params:      CheckParams{URL: server.URL + "/external", FollowRedirects: true, AllowedRedirectHosts: []string{"*.example.com"}},
+			wantLevel:   2,
+			wantMessage: "redirect to unexpected host: " + external,
+		},
+		{
+			name:      "allowed host",
+			params:    CheckParams{URL: server.URL + "/external", FollowRedirects: true, AllowedRedirectHosts: []string{"LOCALHOST"}},
+			wantLevel: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Check(context.Background(), cfg, tt.params)
+
+			if result.AlertLevel != tt.wantLevel {
+				t.Errorf("expected AlertLevel %d, got %d (%s)", tt.wantLevel, result.AlertLevel, result.Message)
+			}
+			if !strings.Contains(result.Message, tt.wantMessage) {
+				t.Errorf("expected message to contain %q, got %q", tt.wantMessage, result.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package urlcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// newRequest builds the HTTP request for a check from its method, headers, body,
+// authentication, Host and User-Agent settings
+func newRequest(ctx context.Context, params CheckParams) (*http.Request, error) {
+	method := strings.ToUpper(params.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	body, err := requestBody(params)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, params.URL, body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	for name, value := range params.Headers {
+		req.Header.Set(name, value)
+	}
+
+	if params.BasicAuthUser != "" {
+		req.SetBasicAuth(params.BasicAuthUser, secret(params.BasicAuthPassword, params.BasicAuthPasswordEnv))
+	}
+	if token := secret(params.BearerToken, params.BearerTokenEnv); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	if params.Host != "" {
+		req.Host = params.Host
+	}
+	if params.UserAgent != "" {
+		req.Header.Set("User-Agent", params.UserAgent)
+	}
+
+	return req, nil
+}
+
+// requestBody returns the request body from the inline body or body file, if any
+func requestBody(params CheckParams) (io.Reader, error) {
+	if params.BodyFile != "" {
+		data, err := os.ReadFile(params.BodyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read body file: %w", err)
+		}
+		return strings.NewReader(string(data)), nil
+	}
+	if params.Body != "" {
+		return strings.NewReader(params.Body), nil
+	}
+	return nil, nil
+}
+
+// secret returns value, or the contents of the environment variable env if value is empty
+func secret(value, env string) string {
+	if value == "" && env != "" {
+		return os.Getenv(env)
+	}
+	return value
+}
+
+// ParseHeader parses a "Name: value" header specification
+func ParseHeader(spec string) (string, string, error) {
+	name, value, ok := strings.Cut(spec, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("invalid header: %s (must be \"Name: value\")", spec)
+	}
+	return name, strings.TrimSpace(value), nil
+}
+
+// ApplyDefaults fills request settings and response assertions missing from each target
+// with those from defaults. Headers are merged, with the target's own headers taking precedence.
+func ApplyDefaults(targets []CheckParams, defaults CheckParams) []CheckParams {
+	for i := range targets {
+		t := &targets[i]
+		t.compiled = nil // the defaults may add assertions
+
+		if t.Method == "" {
+			t.Method = defaults.Method
+		}
+		if t.Body == "" && t.BodyFile == "" {
+			t.Body = defaults.Body
+			t.BodyFile = defaults.BodyFile
+		}
+		if t.BasicAuthUser == "" {
+			t.BasicAuthUser = defaults.BasicAuthUser
+			t.BasicAuthPassword = defaults.BasicAuthPassword
+			t.BasicAuthPasswordEnv = defaults.BasicAuthPasswordEnv
+		}
+		if t.BearerToken == "" && t.BearerTokenEnv == "" {
+			t.BearerToken = defaults.BearerToken
+			t.BearerTokenEnv = defaults.BearerTokenEnv
+		}
+		if t.Host == "" {
+			t.Host = defaults.Host
+		}
+		if t.UserAgent == "" {
+			t.UserAgent = defaults.UserAgent
+		}
+		if !t.FollowRedirects {
+			t.FollowRedirects = defaults.FollowRedirects
+		}
+		if t.MaxRedirects == 0 {
+			t.MaxRedirects = defaults.MaxRedirects
+		}
+		if t.ExpectedFinalURL == "" {
+			t.ExpectedFinalURL = defaults.ExpectedFinalURL
+		}
+		if len(t.AllowedRedirectHosts) == 0 {
+			t.AllowedRedirectHosts = defaults.AllowedRedirectHosts
+		}
+		if t.MinBodyBytes == 0 {
+			t.MinBodyBytes = defaults.MinBodyBytes
+		}
+		if t.MaxBodyBytes == 0 {
+			t.MaxBodyBytes = defaults.MaxBodyBytes
+		}
+		if t.ExpectedSHA256 == "" {
+			t.ExpectedSHA256 = defaults.ExpectedSHA256
+		}
+		if t.BodyRegex == "" {
+			t.BodyRegex = defaults.BodyRegex
+		}
+		if len(t.BodyNotContains) == 0 {
+			t.BodyNotContains = defaults.BodyNotContains
+		}
+		if len(t.HeaderAssertions) == 0 {
+			t.HeaderAssertions = defaults.HeaderAssertions
+		}
+		if len(t.JSONAssertions) == 0 {
+			t.JSONAssertions = defaults.JSONAssertions
+		}
+		if t.JSONValuePath == "" {
+			t.JSONValuePath = defaults.JSONValuePath
+		}
+
+		if len(defaults.Headers) > 0 {
+			headers := make(map[string]string, len(defaults.Headers)+len(t.Headers))
+			for name, value := range defaults.Headers {
+				headers[name] = value
+			}
+			for name, value := range t.Headers {
+				headers[name] = value
+			}
+			t.Headers = headers
+		}
+	}
+
+	return targets
+}
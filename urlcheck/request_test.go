@@ -0,0 +1,133 @@
+package urlcheck
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheck_RequestOptions(t *testing.T) {
+	var got *http.Request
+	var gotBody string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got, gotBody = r, string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := Config{Dashboard: "test", Site: "test-site", Timeout: 5 * time.Second}
+
+	t.Setenv("TEST_URLCHECK_TOKEN", "s3cret")
+	params := CheckParams{
+		URL:            server.URL + "/graphql",
+		Method:         "post",
+		Headers:        map[string]string{"Content-Type": "application/json"},
+		Body:           `{"query":"{ health }"}`,
+		BearerTokenEnv: "TEST_URLCHECK_TOKEN",
+		Host:           "api.example.com",
+		UserAgent:      "alertbingo-test",
+	}
+
+	result := Check(context.Background(), cfg, params)
+	if result.AlertLevel != 0 {
+		t.Fatalf("expected AlertLevel 0, got %d (%s)", result.AlertLevel, result.Message)
+	}
+
+	if got.Method != http.MethodPost {
+		t.Errorf("expected POST, got %s", got.Method)
+	}
+	if got.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("expected Content-Type header, got %q", got.Header.Get("Content-Type"))
+	}
+	if got.Header.Get("Authorization") != "Bearer s3cret" {
+		t.Errorf("expected bearer token from env, got %q", got.Header.Get("Authorization"))
+	}
+	if got.Host != "api.example.com" {
+		t.Errorf("expected Host override, got %q", got.Host)
+	}
+	if got.UserAgent() != "alertbingo-test" {
+		t.Errorf("expected User-Agent, got %q", got.UserAgent())
+	}
+	if gotBody != params.Body {
+		t.Errorf("expected body %q, got %q", params.Body, gotBody)
+	}
+}
+
+func TestCheck_BasicAuthAndBodyFile(t *testing.T) {
+	var gotUser, gotPassword, gotBody string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, gotPassword, _ = r.BasicAuth()
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+	}))
+	defer server.Close()
+
+	bodyFile := filepath.Join(t.TempDir(), "body.json")
+	if err := os.WriteFile(bodyFile, []byte(`{"ping":true}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Config{Dashboard: "test", Site: "test-site", Timeout: 5 * time.Second}
+	params := CheckParams{
+		URL:               server.URL,
+		Method:            http.MethodPut,
+		BodyFile:          bodyFile,
+		BasicAuthUser:     "monitor",
+		BasicAuthPassword: "hunter2",
+	}
+
+	Check(context.Background(), cfg, params)
+
+	if gotUser != "monitor" || gotPassword != "hunter2" {
+		t.Errorf("expected basic auth monitor:hunter2, got %s:%s", gotUser, gotPassword)
+	}
+	if gotBody != `{"ping":true}` {
+		t.Errorf("expected body from file, got %q", gotBody)
+	}
+
+	// A missing body file is reported as an error
+	params.BodyFile = filepath.Join(t.TempDir(), "missing.json")
+	result := Check(context.Background(), cfg, params)
+	if result.AlertLevel != 2 || result.Value != "Error" {
+		t.Errorf("expected error for missing body file, got level %d value %q", result.AlertLevel, result.Value)
+	}
+}
+
+func TestApplyDefaults(t *testing.T) {
+	targets := []CheckParams{
+		{URL: "https://a.example.com"},
+		{URL: "https://b.example.com", Method: "HEAD", Headers: map[string]string{"X-Env": "staging"}},
+	}
+	defaults := CheckParams{
+		Method:  "GET",
+		Headers: map[string]string{"X-Env": "prod", "Accept": "application/json"},
+	}
+
+	got := ApplyDefaults(targets, defaults)
+
+	if got[0].Method != "GET" || got[0].Headers["X-Env"] != "prod" {
+		t.Errorf("expected defaults applied, got %+v", got[0])
+	}
+	if got[1].Method != "HEAD" || got[1].Headers["X-Env"] != "staging" || got[1].Headers["Accept"] != "application/json" {
+		t.Errorf("expected target settings to take precedence, got %+v", got[1])
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	name, value, err := ParseHeader("Authorization: Bearer abc:def")
+	if err != nil || name != "Authorization" || value != "Bearer abc:def" {
+		t.Errorf("ParseHeader() = %q, %q, %v", name, value, err)
+	}
+
+	if _, _, err := ParseHeader("no-colon"); err == nil {
+		t.Error("expected error for header without colon")
+	}
+}
@@ -0,0 +1,367 @@
+package urlcheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alertbingo/alertbingo/api"
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario report modes
+const (
+	ReportSingle = "single" // one check for the whole scenario, naming the failing step
+	ReportSteps  = "steps"  // one check per step
+)
+
+// Scenario is a multi-step synthetic transaction: HTTP requests made in order,
+// sharing a cookie jar, with variables extracted from earlier responses
+type Scenario struct {
+	Name   string `yaml:"name"`
+	Report string `yaml:"report"` // ReportSingle (default) or ReportSteps
+	Steps  []Step `yaml:"steps"`
+}
+
+// Step is a single request in a scenario. Variables referenced as ${name} in its
+// URL, headers, body, credentials and assertions are replaced with values extracted
+// by earlier steps.
+type Step struct {
+	Name        string `yaml:"name"`
+	CheckParams `yaml:",inline"`
+	Extract     map[string]Extractor `yaml:"extract"`
+}
+
+// Extractor extracts a variable from a step's response. Exactly one source is set.
+type Extractor struct {
+	JSON   string `yaml:"json"`   // JSON path, e.g. $.token
+	Regex  string `yaml:"regex"`  // regex on the body; the first group if any, otherwise the match
+	Header string `yaml:"header"` // response header name
+
+	regex *regexp.Regexp // Regex, compiled by validate
+}
+
+// variablePattern matches a ${name} variable reference
+var variablePattern = regexp.MustCompile(`\$\{(\w+)\}`)
+
+// LoadScenario reads and validates a scenario from a YAML file
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario file: %w", err)
+	}
+
+	// Decode strictly, so a misspelt setting isn't silently ignored
+	var scenario Scenario
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&scenario); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse scenario file: %w", err)
+	}
+	if err := scenario.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &scenario, nil
+}
+
+func (s *Scenario) validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("scenario has no name")
+	}
+	if s.Report == "" {
+		s.Report = ReportSingle
+	}
+	if s.Report != ReportSingle && s.Report != ReportSteps {
+		return fmt.Errorf("invalid report mode: %s (must be %s or %s)", s.Report, ReportSingle, ReportSteps)
+	}
+	if len(s.Steps) == 0 {
+		return fmt.Errorf("scenario %s has no steps", s.Name)
+	}
+
+	for i := range s.Steps {
+		step := &s.Steps[i]
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("step %d", i+1)
+		}
+		if step.URL == "" {
+			return fmt.Errorf("%s has no url", step.Name)
+		}
+		if err := ValidateAssertions(&step.CheckParams); err != nil {
+			return fmt.Errorf("%s: %w", step.Name, err)
+		}
+		for name, extractor := range step.Extract {
+			if err := extractor.validate(); err != nil {
+				return fmt.Errorf("%s: variable %s: %w", step.Name, name, err)
+			}
+			step.Extract[name] = extractor
+		}
+	}
+
+	return nil
+}
+
+func (e *Extractor) validate() error {
+	sources := 0
+	for _, source := range []string{e.JSON, e.Regex, e.Header} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return fmt.Errorf("exactly one of json, regex or header must be set")
+	}
+	if e.Regex != "" {
+		var err error
+		if e.regex, err = regexp.Compile(e.Regex); err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+	}
+	return nil
+}
+
+// extract returns the variable's value from a step's result
+func (e Extractor) extract(result Result) (string, error) {
+	switch {
+	case e.Header != "":
+		if value := result.Header.Get(e.Header); value != "" {
+			return value, nil
+		}
+		return "", fmt.Errorf("header %s not found", e.Header)
+	case e.Regex != "":
+		regex := e.regex
+		if regex == nil {
+			var err error
+			if regex, err = regexp.Compile(e.Regex); err != nil {
+				return "", fmt.Errorf("invalid regex: %w", err)
+			}
+		}
+		match := regex.FindSubmatch(result.Body)
+		if match == nil {
+			return "", fmt.Errorf("regex %q did not match", e.Regex)
+		}
+		if len(match) > 1 {
+			return string(match[1]), nil
+		}
+		return string(match[0]), nil
+	default:
+		_, value := evaluateJSON(result.Body, nil, e.JSON)
+		if value == "" {
+			return "", fmt.Errorf("JSON path %s not found", e.JSON)
+		}
+		return value, nil
+	}
+}
+
+// stepResult is the outcome of running a single step
+type stepResult struct {
+	name     string
+	payload  api.CheckPayload // with the step's own message, without cfg.Message
+	duration time.Duration    // zero if no response was received
+	skipped  bool
+}
+
+// RunScenario runs the steps of a scenario in order and returns either one check
+// for the whole scenario or one per step, depending on its report mode. Steps
+// after a failing step are not run.
+func RunScenario(ctx context.Context, cfg Config, scenario *Scenario) []api.CheckPayload {
+	jar, _ := cookiejar.New(nil)
+	client := newClient(cfg)
+	client.Jar = jar
+
+	// Steps often share a URL (e.g. GET and POST /login) and pages embed
+	// per-request tokens, so their content isn't compared with a baseline
+	stepCfg := cfg
+	stepCfg.Message = ""
+	stepCfg.Baseline = nil
+
+	vars := make(map[string]string)
+	var results []stepResult
+	failed := ""
+
+	for _, step := range scenario.Steps {
+		if failed != "" {
+			results = append(results, stepResult{name: step.Name, skipped: true})
+			continue
+		}
+
+		payload, duration := runStep(ctx, stepCfg, client, step, vars)
+		results = append(results, stepResult{name: step.Name, payload: payload, duration: duration})
+		if payload.AlertLevel >= 2 {
+			failed = step.Name
+		}
+	}
+
+	if scenario.Report == ReportSteps {
+		return stepPayloads(cfg, scenario, results, failed)
+	}
+	return []api.CheckPayload{scenarioPayload(cfg, scenario, results, failed)}
+}
+
+// runStep runs a single step, extracting its variables into vars if it succeeds,
+// and returns its check and duration
+func runStep(ctx context.Context, cfg Config, client *http.Client, step Step, vars map[string]string) (api.CheckPayload, time.Duration) {
+	params, err := substituteParams(step.CheckParams, vars)
+	if err != nil {
+		return buildPayload(cfg, step.CheckParams, Result{URL: step.URL, Error: err}), 0
+	}
+
+	result := fetchURL(ctx, client, params, fetchOptions{bodyLimit: cfg.bodyLimit(), keepBody: len(step.Extract) > 0})
+	payload := buildPayload(cfg, params, result)
+	if result.StatusCode == 0 {
+		return payload, 0
+	}
+	if payload.AlertLevel >= 2 {
+		return payload, result.Duration
+	}
+
+	for name, extractor := range step.Extract {
+		value, err := extractor.extract(result)
+		if err != nil {
+			payload.AlertLevel = 2 // alert
+			payload.Message = fmt.Sprintf("failed to extract %s: %v; %s", name, err, payload.Message)
+			return payload, result.Duration
+		}
+		vars[name] = value
+	}
+
+	return payload, result.Duration
+}
+
+// scenarioPayload combines the step results into a single check
+func scenarioPayload(cfg Config, scenario *Scenario, results []stepResult, failed string) api.CheckPayload {
+	payload := api.CheckPayload{
+		Dashboard:        cfg.Dashboard,
+		Site:             cfg.Site,
+		Service:          scenario.Name,
+		Name:             cfg.Name,
+		InactiveExpire:   cfg.InactiveExpire,
+		InactiveEscalate: cfg.InactiveEscalate,
+		Highlighted:      cfg.Highlighted,
+		Value:            "OK",
+	}
+
+	var reasons, timings []string
+	for _, r := range results {
+		if r.skipped {
+			continue
+		}
+		payload.AlertLevel = max(payload.AlertLevel, r.payload.AlertLevel)
+		if r.payload.AlertLevel > 0 {
+			reasons = append(reasons, fmt.Sprintf("step %q: %s", r.name, r.payload.Message))
+		}
+		if r.duration > 0 {
+			timings = append(timings, fmt.Sprintf("%s %s", r.name, formatMillis(r.duration)))
+		} else {
+			timings = append(timings, r.name+" -")
+		}
+	}
+
+	if failed != "" {
+		payload.Value = failed
+	}
+
+	reasons = append(reasons, "steps: "+strings.Join(timings, ", "))
+	payload.Message = appendAlertReason(cfg.Message, strings.Join(reasons, "; "))
+
+	return payload
+}
+
+// stepPayloads returns one check per step, with the service named after the
+// scenario and step. Steps skipped after a failure raise a warning.
+func stepPayloads(cfg Config, scenario *Scenario, results []stepResult, failed string) []api.CheckPayload {
+	checks := make([]api.CheckPayload, len(results))
+
+	for i, r := range results {
+		payload := r.payload
+		if r.skipped {
+			payload = api.CheckPayload{
+				Dashboard:        cfg.Dashboard,
+				Name:             cfg.Name,
+				InactiveExpire:   cfg.InactiveExpire,
+				InactiveEscalate: cfg.InactiveEscalate,
+				Highlighted:      cfg.Highlighted,
+				AlertLevel:       1, // warning
+				Value:            "Skipped",
+				Message:          fmt.Sprintf("not run: step %q failed", failed),
+			}
+		}
+
+		payload.Site = cfg.Site
+		payload.Service = scenario.Name + ": " + r.name
+		payload.Message = appendAlertReason(cfg.Message, payload.Message)
+		checks[i] = payload
+	}
+
+	return checks
+}
+
+// substituteParams returns a copy of params with ${name} variables replaced in
+// the URL, headers, body, credentials and assertions
+func substituteParams(params CheckParams, vars map[string]string) (CheckParams, error) {
+	var missing []string
+	sub := func(s string) string {
+		return variablePattern.ReplaceAllStringFunc(s, func(ref string) string {
+			name := variablePattern.FindStringSubmatch(ref)[1]
+			value, ok := vars[name]
+			if !ok && !slices.Contains(missing, name) {
+				missing = append(missing, name)
+			}
+			return value
+		})
+	}
+	subAll := func(list []string) []string {
+		if list == nil {
+			return nil
+		}
+		out := make([]string, len(list))
+		for i, s := range list {
+			out[i] = sub(s)
+		}
+		return out
+	}
+
+	original := params
+	params.URL = sub(params.URL)
+	params.Body = sub(params.Body)
+	params.BodyFile = sub(params.BodyFile)
+	params.BearerToken = sub(params.BearerToken)
+	params.UserAgent = sub(params.UserAgent)
+	params.Host = sub(params.Host)
+	params.BasicAuthUser = sub(params.BasicAuthUser)
+	params.ExpectedBody = sub(params.ExpectedBody)
+	params.ExpectedFinalURL = sub(params.ExpectedFinalURL)
+	params.BodyRegex = sub(params.BodyRegex)
+	params.BodyNotContains = subAll(params.BodyNotContains)
+	params.HeaderAssertions = subAll(params.HeaderAssertions)
+	params.JSONAssertions = subAll(params.JSONAssertions)
+
+	if params.Headers != nil {
+		headers := make(map[string]string, len(params.Headers))
+		for name, value := range params.Headers {
+			headers[name] = sub(value)
+		}
+		params.Headers = headers
+	}
+
+	// Assertions that used variables are compiled again when the step runs
+	if params.BodyRegex != original.BodyRegex ||
+		!slices.Equal(params.HeaderAssertions, original.HeaderAssertions) ||
+		!slices.Equal(params.JSONAssertions, original.JSONAssertions) {
+		params.compiled = nil
+	}
+
+	if len(missing) > 0 {
+		return params, fmt.Errorf("undefined variable: %s", strings.Join(missing, ", "))
+	}
+	return params, nil
+}
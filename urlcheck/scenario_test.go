@@ -0,0 +1,253 @@
+package urlcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newLoginServer serves a login form with a CSRF token, a login endpoint that
+// requires the session cookie and token, and an API that requires the issued bearer token
+func newLoginServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s1"})
+		fmt.Fprint(w, `<form><input name="csrf" value="c0ffee"></form>`)
+	})
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("session")
+		if err != nil || cookie.Value != "s1" || r.FormValue("csrf") != "c0ffee" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("X-Account", "42")
+		fmt.Fprint(w, `{"token":"t1"}`)
+	})
+	mux.HandleFunc("GET /api/accounts/42", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t1" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"user":"monitor"}`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func loginScenario(url, report, user string) *Scenario {
+	return &Scenario{
+		Name:   "login",
+		Report: report,
+		Steps: []Step{
+			{
+				Name:        "login page",
+				CheckParams: CheckParams{URL: url + "/login"},
+				Extract:     map[string]Extractor{"csrf": {Regex: `name="csrf" value="([^"]+)"`}},
+			},
+			{
+				Name: "submit",
+				CheckParams: CheckParams{
+					URL:     url + "/login",
+					Method:  http.MethodPost,
+					Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+					Body:    "user=monitor&csrf=${csrf}",
+				},
+				Extract: map[string]Extractor{"token": {JSON: "$.token"}, "account": {Header: "X-Account"}},
+			},
+			{
+				Name: "account",
+				CheckParams: CheckParams{
+					URL:            url + "/api/accounts/${account}",
+					Headers:        map[string]string{"Authorization": "Bearer ${token}"},
+					JSONAssertions: []string{"user == " + user},
+				},
+			},
+		},
+	}
+}
+
+func TestRunScenario(t *testing.T) {
+	server := newLoginServer(t)
+	cfg := Config{Dashboard: "test", Site: "test-site", Name: "synthetic", Message: "Login flow", Timeout: 5 * time.Second}
+
+	checks := RunScenario(context.Background(), cfg, loginScenario(server.URL, ReportSingle, "monitor"))
+	if len(checks) != 1 {
+		t.Fatalf("expected 1 check, got %d", len(checks))
+	}
+	if checks[0].AlertLevel != 0 || checks[0].Value != "OK" || checks[0].Service != "login" {
+		t.Errorf("expected OK login check, got level %d value %q service %q (%s)", checks[0].AlertLevel, checks[0].Value, checks[0].Service, checks[0].Message)
+	}
+	if !strings.HasPrefix(checks[0].Message, "Login flow - steps: login page ") || !strings.Contains(checks[0].Message, ", account ") {
+		t.Errorf("expected step timings in message, got %q", checks[0].Message)
+	}
+
+	// A failing assertion names the failing step
+	checks = RunScenario(context.Background(), cfg, loginScenario(server.URL, ReportSingle, "admin"))
+	if checks[0].AlertLevel != 2 || checks[0].Value != "account" {
+		t.Errorf("expected alert naming the account step, got level %d value %q (%s)", checks[0].AlertLevel, checks[0].Value, checks[0].Message)
+	}
+	if !strings.Contains(checks[0].Message, `step "account": user == admin: got "monitor"`) {
+		t.Errorf("expected failing assertion in message, got %q", checks[0].Message)
+	}
+}
+
+func TestRunScenario_Baseline(t *testing.T) {
+	server := newLoginServer(t)
+	baseline, err := LoadBaseline(filepath.Join(t.TempDir(), "baseline.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{Dashboard: "test", Site: "test-site", Name: "synthetic", Timeout: 5 * time.Second, Baseline: baseline}
+
+	// The GET and POST to /login share a URL but return different content
+	for run := 1; run <= 2; run++ {
+		checks := RunScenario(context.Background(), cfg, loginScenario(server.URL, ReportSteps, "monitor"))
+		for _, check := range checks {
+			if check.AlertLevel != 0 {
+				t.Errorf("run %d: expected %s to be OK, got level %d (%s)", run, check.Service, check.AlertLevel, check.Message)
+			}
+		}
+	}
+}
+
+func TestRunScenario_Steps(t *testing.T) {
+	server := newLoginServer(t)
+	cfg := Config{Dashboard: "test", Site: "test-site", Timeout: 5 * time.Second}
+
+	scenario := loginScenario(server.URL, ReportSteps, "monitor")
+	// The first step fails to extract the CSRF token, so the later steps are skipped
+	scenario.Steps[0].Extract = map[string]Extractor{"csrf": {Regex: `name="token" value="([^"]+)"`}}
+
+	checks := RunScenario(context.Background(), cfg, scenario)
+	if len(checks) != 3 {
+		t.Fatalf("expected 3 checks, got %d", len(checks))
+	}
+
+	want := []struct {
+		service string
+		level   int
+		message string
+	}{
+		{"login: login page", 2, "failed to extract csrf: regex"},
+		{"login: submit", 1, `not run: step "login page" failed`},
+		{"login: account", 1, `not run: step "login page" failed`},
+	}
+	for i, w := range want {
+		if checks[i].Service != w.service || checks[i].AlertLevel != w.level || !strings.Contains(checks[i].Message, w.message) {
+			t.Errorf("check %d: expected %s level %d containing %q, got %s level %d (%s)",
+				i, w.service, w.level, w.message, checks[i].Service, checks[i].AlertLevel, checks[i].Message)
+		}
+	}
+}
+
+func TestSubstituteParams(t *testing.T) {
+	params := CheckParams{
+		URL:         "https://example.com/${a}/${b}",
+		Headers:     map[string]string{"X-Token": "${token}"},
+		BearerToken: "${token}",
+		UserAgent:   "probe/${a}",
+		BodyFile:    "/tmp/${b}.json",
+		BodyRegex:   "order ${a}",
+	}
+
+	got, err := substituteParams(params, map[string]string{"a": "1", "b": "2", "token": "t"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.URL != "https://example.com/1/2" || got.Headers["X-Token"] != "t" {
+		t.Errorf("unexpected substitution: %+v", got)
+	}
+	if got.BearerToken != "t" || got.UserAgent != "probe/1" || got.BodyFile != "/tmp/2.json" || got.BodyRegex != "order 1" {
+		t.Errorf("unexpected substitution: %+v", got)
+	}
+	if params.Headers["X-Token"] != "${token}" {
+		t.Error("expected original headers to be unchanged")
+	}
+
+	// Compiled assertions are kept unless variables changed them
+	params.JSONAssertions = []string{"id == ${a}"}
+	if err := ValidateAssertions(&params); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := substituteParams(params, map[string]string{"a": "1", "b": "2", "token": "t"}); got.compiled != nil {
+		t.Error("expected assertions using variables to be compiled again")
+	}
+	params.JSONAssertions = []string{"id exists"}
+	params.BodyRegex = "order"
+	if err := ValidateAssertions(&params); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := substituteParams(params, map[string]string{"a": "1", "b": "2", "token": "t"}); got.compiled != params.compiled {
+		t.Error("expected assertions without variables to stay compiled")
+	}
+
+	if _, err := substituteParams(params, map[string]string{"a": "1"}); err == nil || !strings.Contains(err.Error(), "undefined variable: b, token") {
+		t.Errorf("expected undefined variable error, got %v", err)
+	}
+}
+
+func TestLoadScenario(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := `name: checkout
+report: steps
+steps:
+  - url: https://shop.example.com/
+    expected_code: 200
+    extract:
+      product: {regex: 'data-product="(\w+)"'}
+  - name: add to cart
+    url: https://shop.example.com/cart
+    method: POST
+    body: 'product=${product}'
+    json_assertions: ["items == 1"]
+`
+	path := filepath.Join(dir, "valid.yaml")
+	if err := os.WriteFile(path, []byte(valid), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	scenario, err := LoadScenario(path)
+	if err != nil {
+		t.Fatalf("LoadScenario() error = %v", err)
+	}
+	if scenario.Report != ReportSteps || len(scenario.Steps) != 2 || scenario.Steps[0].Name != "step 1" {
+		t.Errorf("unexpected scenario: %+v", scenario)
+	}
+	if scenario.Steps[1].Method != "POST" || scenario.Steps[1].JSONAssertions[0] != "items == 1" {
+		t.Errorf("expected inline check params, got %+v", scenario.Steps[1].CheckParams)
+	}
+	if scenario.Steps[0].Extract["product"].regex == nil {
+		t.Error("expected the extractor regex to be kept compiled")
+	}
+
+	invalid := map[string]string{
+		"no name":          "steps:\n  - url: https://example.com\n",
+		"no steps":         "name: empty\n",
+		"bad report":       "name: x\nreport: all\nsteps:\n  - url: https://example.com\n",
+		"two sources":      "name: x\nsteps:\n  - url: https://example.com\n    extract:\n      v: {json: a, header: b}\n",
+		"step without url": "name: x\nsteps:\n  - name: first\n",
+		"unknown setting":  "name: x\nsteps:\n  - url: https://example.com\n    extrct:\n      v: {json: a}\n",
+	}
+	for name, content := range invalid {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(name, " ", "-")+".yaml")
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadScenario(path); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
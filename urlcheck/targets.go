@@ -0,0 +1,136 @@
+package urlcheck
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseTarget parses a compact target specification of the form
+// "<url> [expected_codes] [expected_body]", where the codes are a single status
+// code or a list such as "200,204,3xx" and the body is the rest of the line. A
+// URL without a scheme defaults to https.
+func ParseTarget(spec string) (CheckParams, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return CheckParams{}, fmt.Errorf("empty target")
+	}
+
+	params := CheckParams{URL: withScheme(fields[0])}
+
+	if len(fields) >= 2 {
+		if code, err := strconv.Atoi(fields[1]); err == nil {
+			params.ExpectedCode = code
+		} else if _, err := ParseStatusCodes(fields[1]); err == nil {
+			params.ExpectedCodes = fields[1]
+		} else {
+			return CheckParams{}, fmt.Errorf("invalid expected status code: %s", fields[1])
+		}
+	}
+
+	if len(fields) >= 3 {
+		// Keep the body's original spacing
+		rest := strings.TrimSpace(spec)
+		for _, field := range fields[:2] {
+			rest = strings.TrimSpace(strings.TrimPrefix(rest, field))
+		}
+		params.ExpectedBody = rest
+	}
+
+	return params, nil
+}
+
+// ParseTargetArgs parses command line arguments into check targets. Every argument
+// is a compact target specification (see ParseTarget), except in the positional
+// form: a URL followed by its expected status codes and optionally the body.
+func ParseTargetArgs(args []string) ([]CheckParams, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	// Positional form: <url> <expected_codes> [expected_body]. A host is never a
+	// valid list of status codes, so this can't be mistaken for several targets.
+	if len(args) == 2 || len(args) == 3 {
+		if _, err := ParseStatusCodes(args[1]); err == nil {
+			params, err := ParseTarget(args[0] + " " + args[1])
+			if err != nil {
+				return nil, err
+			}
+			if len(args) == 3 {
+				params.ExpectedBody = args[2]
+			}
+			return []CheckParams{params}, nil
+		}
+	}
+
+	var targets []CheckParams
+	for _, arg := range args {
+		params, err := ParseTarget(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target %q: %w", arg, err)
+		}
+		targets = append(targets, params)
+	}
+
+	return targets, nil
+}
+
+// LoadTargets reads check targets from a file. Files with a .yaml or .yml extension
+// contain a YAML list of targets; otherwise each non-empty line that doesn't start
+// with # is a compact target specification.
+func LoadTargets(path string) ([]CheckParams, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read targets file: %w", err)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".yaml" || ext == ".yml" {
+		var targets []CheckParams
+		if err := yaml.Unmarshal(data, &targets); err != nil {
+			return nil, fmt.Errorf("failed to parse targets file: %w", err)
+		}
+		for i, target := range targets {
+			if target.URL == "" {
+				return nil, fmt.Errorf("target %d in %s has no url", i+1, path)
+			}
+		}
+		return targets, nil
+	}
+
+	var targets []CheckParams
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		params, err := ParseTarget(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNum, err)
+		}
+		targets = append(targets, params)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read targets file: %w", err)
+	}
+
+	return targets, nil
+}
+
+// withScheme defaults a URL without a scheme to https
+func withScheme(rawURL string) string {
+	if strings.Contains(rawURL, "://") {
+		return rawURL
+	}
+	return "https://" + rawURL
+}
@@ -0,0 +1,115 @@
+package urlcheck
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseTargetArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []CheckParams
+		wantErr bool
+	}{
+		{
+			name: "single URL",
+			args: []string{"https://example.com"},
+			want: []CheckParams{{URL: "https://example.com"}},
+		},
+		{
+			name: "positional code and body",
+			args: []string{"https://example.com", "200", "Hello  World"},
+			want: []CheckParams{{URL: "https://example.com", ExpectedCode: 200, ExpectedBody: "Hello  World"}},
+		},
+		{
+			name: "multiple URLs",
+			args: []string{"https://a.example.com", "https://b.example.com 201", "https://c.example.com 200 status ok"},
+			want: []CheckParams{
+				{URL: "https://a.example.com"},
+				{URL: "https://b.example.com", ExpectedCode: 201},
+				{URL: "https://c.example.com", ExpectedCode: 200, ExpectedBody: "status ok"},
+			},
+		},
+		{
+			name: "status code list",
+			args: []string{"https://example.com", "200,204,3xx"},
+			want: []CheckParams{{URL: "https://example.com", ExpectedCodes: "200,204,3xx"}},
+		},
+		{
+			name: "bare hosts",
+			args: []string{"example.com", "other.com"},
+			want: []CheckParams{{URL: "https://example.com"}, {URL: "https://other.com"}},
+		},
+		{
+			name: "bare host with code",
+			args: []string{"example.com", "301"},
+			want: []CheckParams{{URL: "https://example.com", ExpectedCode: 301}},
+		},
+		{
+			name:    "invalid code",
+			args:    []string{"https://example.com abc"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTargetArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTargetArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTargetArgs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadTargets(t *testing.T) {
+	dir := t.TempDir()
+
+	listPath := filepath.Join(dir, "targets.txt")
+	list := "# health checks\nhttps://a.example.com\n\nhttps://b.example.com 204\n"
+	if err := os.WriteFile(listPath, []byte(list), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	yamlPath := filepath.Join(dir, "targets.yaml")
+	yamlData := "- url: https://a.example.com\n- url: https://b.example.com\n  expected_code: 204\n  expected_body: ok\n"
+	if err := os.WriteFile(yamlPath, []byte(yamlData), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []CheckParams{
+		{URL: "https://a.example.com"},
+		{URL: "https://b.example.com", ExpectedCode: 204},
+	}
+
+	got, err := LoadTargets(listPath)
+	if err != nil {
+		t.Fatalf("LoadTargets(list) error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadTargets(list) = %+v, want %+v", got, want)
+	}
+
+	want[1].ExpectedBody = "ok"
+	got, err = LoadTargets(yamlPath)
+	if err != nil {
+		t.Fatalf("LoadTargets(yaml) error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadTargets(yaml) = %+v, want %+v", got, want)
+	}
+
+	badPath := filepath.Join(dir, "bad.yml")
+	if err := os.WriteFile(badPath, []byte("- expected_code: 200\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTargets(badPath); err == nil {
+		t.Error("expected error for target without url")
+	}
+}
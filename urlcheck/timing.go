@@ -0,0 +1,166 @@
+package urlcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSlowThreshold is the total duration above which a successful request is
+// considered slow when no threshold is configured
+const DefaultSlowThreshold = 2 * time.Second
+
+// Timing is the breakdown of a request's duration by phase. Phases that did not
+// happen (e.g. DNS and connect on a reused connection, TLS for plain HTTP) are zero.
+type Timing struct {
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	TTFB    time.Duration // from sending the request to the first response byte
+	Total   time.Duration // from start to the end of the response body
+}
+
+// String returns the timing breakdown, e.g. "dns 4ms, connect 12ms, tls 31ms, ttfb 80ms, total 95ms"
+func (t Timing) String() string {
+	var parts []string
+	for _, phase := range []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"dns", t.DNS},
+		{"connect", t.Connect},
+		{"tls", t.TLS},
+		{"ttfb", t.TTFB},
+	} {
+		if phase.duration > 0 {
+			parts = append(parts, fmt.Sprintf("%s %s", phase.name, formatMillis(phase.duration)))
+		}
+	}
+	parts = append(parts, "total "+formatMillis(t.Total))
+	return strings.Join(parts, ", ")
+}
+
+// SlowThresholds are the durations above which a successful request raises a warning
+type SlowThresholds struct {
+	Total   time.Duration // 0 means DefaultSlowThreshold
+	DNS     time.Duration // 0 disables the check for this phase
+	Connect time.Duration
+	TLS     time.Duration
+	TTFB    time.Duration
+}
+
+// Exceeded returns a reason for each phase of t that is slower than its threshold
+func (s SlowThresholds) Exceeded(t Timing) []string {
+	total := s.Total
+	if total == 0 {
+		total = DefaultSlowThreshold
+	}
+
+	var reasons []string
+	if t.Total > total {
+		reasons = append(reasons, fmt.Sprintf("slow response: %.2fs", t.Total.Seconds()))
+	}
+	for _, phase := range []struct {
+		name                string
+		duration, threshold time.Duration
+	}{
+		{"dns", t.DNS, s.DNS},
+		{"connect", t.Connect, s.Connect},
+		{"tls", t.TLS, s.TLS},
+		{"ttfb", t.TTFB, s.TTFB},
+	} {
+		if phase.threshold > 0 && phase.duration > phase.threshold {
+			reasons = append(reasons, fmt.Sprintf("slow %s: %s > %s", phase.name, formatMillis(phase.duration), formatMillis(phase.threshold)))
+		}
+	}
+	return reasons
+}
+
+// tracer records the phase timings of a request through httptrace hooks, which may
+// be called from the transport's goroutines
+type tracer struct {
+	mu                      sync.Mutex
+	start                   time.Time
+	dnsStart, connectStart  time.Time
+	tlsStart, wroteRequest  time.Time
+	dns, connect, tls, ttfb time.Duration
+}
+
+// withTrace returns a context that records the request's timings in a new tracer
+func withTrace(ctx context.Context) (context.Context, *tracer) {
+	t := &tracer{start: time.Now()}
+
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dns = time.Since(t.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			// With several addresses, time from the first attempt
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(_, _ string, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if err == nil {
+				t.connect = time.Since(t.connectStart)
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tls = time.Since(t.tlsStart)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.wroteRequest = time.Now()
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if !t.wroteRequest.IsZero() {
+				t.ttfb = time.Since(t.wroteRequest)
+			}
+		},
+	}
+
+	return httptrace.WithClientTrace(ctx, trace), t
+}
+
+// timing returns the recorded phase timings with the total duration up to now
+func (t *tracer) timing() Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return Timing{
+		DNS:     t.dns,
+		Connect: t.connect,
+		TLS:     t.tls,
+		TTFB:    t.ttfb,
+		Total:   time.Since(t.start),
+	}
+}
+
+// formatMillis formats a duration in whole milliseconds
+func formatMillis(d time.Duration) string {
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
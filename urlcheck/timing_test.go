@@ -0,0 +1,113 @@
+package urlcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTiming_String(t *testing.T) {
+	timing := Timing{DNS: 4 * time.Millisecond, TLS: 31 * time.Millisecond, TTFB: 80 * time.Millisecond, Total: 95 * time.Millisecond}
+
+	if got, want := timing.String(), "dns 4ms, tls 31ms, ttfb 80ms, total 95ms"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestSlowThresholds_Exceeded(t *testing.T) {
+	timing := Timing{DNS: 300 * time.Millisecond, Connect: 10 * time.Millisecond, TTFB: 900 * time.Millisecond, Total: 2500 * time.Millisecond}
+
+	tests := []struct {
+		name       string
+		thresholds SlowThresholds
+		want       []string
+	}{
+		{"default total", SlowThresholds{}, []string{"slow response: 2.50s"}},
+		{"raised total", SlowThresholds{Total: 5 * time.Second}, nil},
+		{
+			name:       "phases",
+			thresholds: SlowThresholds{Total: 5 * time.Second, DNS: 200 * time.Millisecond, Connect: 200 * time.Millisecond, TTFB: 500 * time.Millisecond},
+			want:       []string{"slow dns: 300ms > 200ms", "slow ttfb: 900ms > 500ms"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.thresholds.Exceeded(timing); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Exceeded() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck_Timing(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	cfg := Config{
+		Dashboard: "test",
+		Site:      "test-site",
+		Timeout:   5 * time.Second,
+		TLSConfig: server.Client().Transport.(*http.Transport).TLSClientConfig,
+	}
+	params := CheckParams{URL: server.URL}
+
+	result := fetchURL(context.Background(), newClient(cfg), params, fetchOptions{bodyLimit: DefaultBodyLimit})
+	if result.Error != nil {
+		t.Fatalf("unexpected error: %v", result.Error)
+	}
+	if result.Timing.Connect <= 0 || result.Timing.TLS <= 0 {
+		t.Errorf("expected connect and TLS timings, got %+v", result.Timing)
+	}
+	if result.Timing.TTFB < 50*time.Millisecond || result.Timing.Total < result.Timing.TTFB {
+		t.Errorf("expected TTFB of at least 50ms within the total, got %+v", result.Timing)
+	}
+
+	payload := Check(context.Background(), cfg, params)
+	if payload.AlertLevel != 0 || payload.Message != "" {
+		t.Errorf("expected OK without timing in the message, got level %d (%s)", payload.AlertLevel, payload.Message)
+	}
+	if tls, ok := payload.Measurement.Values["tls"].(time.Duration); !ok || tls <= 0 {
+		t.Errorf("expected TLS timing in the measurement, got %v", payload.Measurement.Values["tls"])
+	}
+
+	cfg.SlowThresholds.TTFB = 10 * time.Millisecond
+	payload = Check(context.Background(), cfg, params)
+	if payload.AlertLevel != 1 || !strings.Contains(payload.Message, "slow ttfb") || !strings.Contains(payload.Message, "tls ") {
+		t.Errorf("expected slow TTFB warning, got level %d (%s)", payload.AlertLevel, payload.Message)
+	}
+}
+
+func TestCheck_TimingEndlessBody(t *testing.T) {
+	// A body that never ends must be read only up to the limit, not until the timeout
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chunk := []byte(strings.Repeat("a", 4096))
+		for r.Context().Err() == nil {
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	cfg := Config{Dashboard: "test", Site: "test-site", Timeout: 5 * time.Second, BodyLimit: 64 << 10}
+	params := CheckParams{URL: server.URL}
+
+	result := fetchURL(context.Background(), newClient(cfg), params, fetchOptions{bodyLimit: cfg.bodyLimit()})
+	if result.Error != nil {
+		t.Fatalf("unexpected error: %v", result.Error)
+	}
+	if !result.BodyTruncated || result.BodySize != cfg.BodyLimit {
+		t.Errorf("expected body truncated at %d bytes, got %d (truncated %v)", cfg.BodyLimit, result.BodySize, result.BodyTruncated)
+	}
+	if result.Timing.Total >= cfg.Timeout {
+		t.Errorf("expected the read to stop at the limit, took %s", result.Timing.Total)
+	}
+}
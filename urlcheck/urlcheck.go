@@ -3,13 +3,15 @@ package urlcheck
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/alertbingo/alertbingo/api"
+	"github.com/alertbingo/alertbingo/internal/checkutil"
 )
 
 // Config holds the common configuration for URL checks
@@ -22,57 +24,276 @@ type Config struct {
 	InactiveEscalate string
 	Highlighted      string
 	Timeout          time.Duration
+	TLSConfig        *tls.Config // CAs, client certificates and verification settings; may be nil
+	SlowThresholds   SlowThresholds
+	BodyLimit        int64                                 // maximum response body bytes read; 0 means DefaultBodyLimit
+	Baseline         *Baseline                             // last seen content hashes; a change raises a warning
+	Proxy            func(*http.Request) (*url.URL, error) // overrides the proxy from the environment; may be nil
+	Network          string                                // "tcp4" or "tcp6" to force IPv4 or IPv6; empty allows either
+	Resolve          []Resolve                             // addresses to connect to instead of resolving hosts
+	EachAddress      bool                                  // check each address a host resolves to separately
+	Attempts         int                                   // attempts before alerting; 0 or 1 means a single attempt
+	AttemptDelay     time.Duration                         // delay between attempts
+
+	address string // the address checked with EachAddress, which identifies the check along with the URL
 }
 
 // CheckParams holds the parameters for a single URL check
 type CheckParams struct {
-	URL          string
-	ExpectedCode int    // 0 means any 2xx is OK
-	ExpectedBody string // empty means no body check
+	URL           string `yaml:"url"`
+	ExpectedCode  int    `yaml:"expected_code"`  // 0 means any 2xx is OK
+	ExpectedCodes string `yaml:"expected_codes"` // e.g. "200,204,3xx"; overrides ExpectedCode
+	ExpectedBody  string `yaml:"expected_body"`  // empty means no body check
+
+	BodyRegex        string   `yaml:"body_regex"`        // regular expression the body must match
+	BodyNotContains  []string `yaml:"body_not_contains"` // strings the body must not contain
+	HeaderAssertions []string `yaml:"header_assertions"` // e.g. "Content-Type =~ ^application/json"
+
+	Method               string            `yaml:"method"` // defaults to GET
+	Headers              map[string]string `yaml:"headers"`
+	Body                 string            `yaml:"body"`
+	BodyFile             string            `yaml:"body_file"` // read the request body from a file
+	BasicAuthUser        string            `yaml:"basic_auth_user"`
+	BasicAuthPassword    string            `yaml:"-"`
+	BasicAuthPasswordEnv string            `yaml:"basic_auth_password_env"` // environment variable holding the password
+	BearerToken          string            `yaml:"-"`
+	BearerTokenEnv       string            `yaml:"bearer_token_env"` // environment variable holding the token
+	Host                 string            `yaml:"host"`             // Host header override
+	UserAgent            string            `yaml:"user_agent"`
+
+	FollowRedirects      bool     `yaml:"follow_redirects"`
+	MaxRedirects         int      `yaml:"max_redirects"`          // 0 means DefaultMaxRedirects
+	ExpectedFinalURL     string   `yaml:"expected_final_url"`     // prefix the URL after redirects must start with
+	AllowedRedirectHosts []string `yaml:"allowed_redirect_hosts"` // e.g. www.example.com or *.example.com; empty allows any
+
+	MinBodyBytes   int64  `yaml:"min_body_bytes"`
+	MaxBodyBytes   int64  `yaml:"max_body_bytes"`
+	ExpectedSHA256 string `yaml:"expected_sha256"` // hex SHA-256 the body must have
+
+	JSONAssertions []string `yaml:"json_assertions"` // e.g. "db == ok", "queue_depth < 1000"
+	JSONValuePath  string   `yaml:"json_value"`      // path whose value is reported as the check's Value
+
+	compiled *compiledAssertions // set by ValidateAssertions
 }
 
 // Result holds the result of a URL check
 type Result struct {
-	URL        string
-	StatusCode int
-	BodyMatch  bool
-	Error      error
-	Duration   time.Duration
-	IsTimeout  bool
+	URL            string
+	StatusCode     int
+	BodyMatch      bool
+	BodyFailures   []string // failed regex and "must not contain" body checks
+	HeaderFailures []string // failed header assertions
+	JSONFailures   []string // failed JSON assertions
+	BodySize       int64    // bytes of the body read, at most the body limit
+	BodyTruncated  bool     // the body was larger than the body limit
+	ContentHash    string   // hex SHA-256 of the body read
+	Header         http.Header
+	Body           []byte // the body read, if kept (see fetchOptions)
+	FinalURL       string // URL of the final response after any redirects
+	Redirects      string // the redirect chain, if redirects were followed
+	RedirectError  string // loop, hop limit or unexpected host that stopped the redirects
+	JSONValue      string // value at CheckParams.JSONValuePath, if found
+	Error          error
+	Duration       time.Duration // total duration, including reading the body
+	Timing         Timing
+	IsTimeout      bool
 }
 
-// Check performs a URL check and returns a CheckPayload
+// Check performs a URL check and returns a CheckPayload. With several attempts,
+// a failed attempt is retried after the attempt delay; the check only alerts if
+// every attempt fails, and warns if an attempt failed before one succeeded.
 func Check(ctx context.Context, cfg Config, params CheckParams) api.CheckPayload {
-	result := fetchURL(ctx, params, cfg.Timeout)
+	if cfg.Attempts <= 1 {
+		return checkOnce(ctx, cfg, params)
+	}
+
+	attemptCfg := cfg
+	attemptCfg.Message = ""
+
+	var payload api.CheckPayload
+	var failures []string
+
+	for attempt := 1; attempt <= cfg.Attempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(cfg.AttemptDelay):
+			}
+		}
+
+		payload = checkOnce(ctx, attemptCfg, params)
+		if payload.AlertLevel < 2 {
+			break
+		}
+		failures = append(failures, fmt.Sprintf("attempt %d: %s", attempt, payload.Message))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	switch {
+	case payload.AlertLevel >= 2:
+		payload.Message = fmt.Sprintf("all %d attempts failed: %s", len(failures), strings.Join(failures, "; "))
+	case len(failures) > 0:
+		payload.AlertLevel = max(payload.AlertLevel, 1) // warning
+		payload.Message = fmt.Sprintf("%d of %d attempts failed (%s); %s",
+			len(failures), len(failures)+1, strings.Join(failures, "; "), payload.Message)
+	}
+	payload.Message = appendAlertReason(cfg.Message, payload.Message)
+
+	return payload
+}
+
+// checkOnce performs a single attempt of a URL check
+func checkOnce(ctx context.Context, cfg Config, params CheckParams) api.CheckPayload {
+	result := fetchURL(ctx, newClient(cfg), params, fetchOptions{bodyLimit: cfg.bodyLimit()})
 	return buildPayload(cfg, params, result)
 }
 
-// fetchURL performs the HTTP request and checks the response
-func fetchURL(ctx context.Context, params CheckParams, timeout time.Duration) Result {
+// CheckAll checks the given targets concurrently, running at most workers requests
+// at a time, and returns their payloads in the same order as the targets. With
+// cfg.EachAddress, each address a target's host resolves to is checked and
+// reported separately, with the address in the service.
+func CheckAll(ctx context.Context, cfg Config, targets []CheckParams, workers int) []api.CheckPayload {
+	jobs := addressJobs(ctx, cfg, targets, workers)
+	checks := make([]api.CheckPayload, len(jobs))
+	checkutil.ForEach(len(jobs), workers, func(i int) {
+		checks[i] = jobs[i].run(ctx)
+	})
+	return checks
+}
+
+// addressJob is a check of a target, optionally pinned to one of its addresses
+type addressJob struct {
+	cfg    Config
+	params CheckParams
+	err    error // address resolution failure
+}
+
+func (j addressJob) run(ctx context.Context) api.CheckPayload {
+	if j.err != nil {
+		return buildPayload(j.cfg, j.params, Result{URL: j.params.URL, Error: j.err})
+	}
+	return Check(ctx, j.cfg, j.params)
+}
+
+// addressJobs returns the checks to run for the targets: one per target, or with
+// cfg.EachAddress one per address each target's host resolves to. Hosts are
+// resolved concurrently, at most workers at a time.
+func addressJobs(ctx context.Context, cfg Config, targets []CheckParams, workers int) []addressJob {
+	if !cfg.EachAddress {
+		jobs := make([]addressJob, len(targets))
+		for i, params := range targets {
+			jobs[i] = addressJob{cfg: cfg, params: params}
+		}
+		return jobs
+	}
+
+	targetJobs := make([][]addressJob, len(targets))
+	checkutil.ForEach(len(targets), workers, func(i int) {
+		params := targets[i]
+		addresses, err := resolveAddresses(ctx, cfg, params.URL)
+		if err != nil {
+			targetJobs[i] = []addressJob{{cfg: cfg, params: params, err: err}}
+			return
+		}
+		for _, address := range addresses {
+			targetJobs[i] = append(targetJobs[i], addressJob{cfg: pinAddress(cfg, params.URL, address), params: params})
+		}
+	})
+
+	var jobs []addressJob
+	for _, j := range targetJobs {
+		jobs = append(jobs, j...)
+	}
+	return jobs
+}
+
+// checkKey identifies a check of a URL in the service and the baseline,
+// including the address checked with EachAddress
+func (cfg Config) checkKey(rawURL string) string {
+	if cfg.address == "" {
+		return rawURL
+	}
+	return fmt.Sprintf("%s (%s)", rawURL, cfg.address)
+}
+
+// bodyLimit returns the maximum number of body bytes to read
+func (cfg Config) bodyLimit() int64 {
+	if cfg.BodyLimit <= 0 {
+		return DefaultBodyLimit
+	}
+	return cfg.BodyLimit
+}
+
+// newClient creates the HTTP client used for URL checks. Redirects are not followed
+// unless a check enables them (see fetchURL).
+func newClient(cfg Config) *http.Client {
 	client := &http.Client{
-		Timeout: timeout,
+		Timeout: cfg.Timeout,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		},
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, params.URL, nil)
+	if cfg.TLSConfig != nil || cfg.Proxy != nil || cfg.needsDialer() {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if cfg.TLSConfig != nil {
+			transport.TLSClientConfig = cfg.TLSConfig.Clone()
+		}
+		if cfg.Proxy != nil {
+			transport.Proxy = cfg.Proxy
+		}
+		if len(cfg.Resolve) > 0 {
+			transport.Proxy = directForResolved(transport.Proxy, cfg.Resolve)
+		}
+		if cfg.needsDialer() {
+			transport.DialContext = cfg.dialContext()
+		}
+		client.Transport = transport
+	}
+
+	return client
+}
+
+// fetchOptions controls how fetchURL reads the response
+type fetchOptions struct {
+	bodyLimit int64 // maximum body bytes to read
+	keepBody  bool  // keep the body in Result.Body
+}
+
+// fetchURL performs the HTTP request and checks the response
+func fetchURL(ctx context.Context, client *http.Client, params CheckParams, opts fetchOptions) Result {
+	assertions, err := params.assertions()
 	if err != nil {
-		return Result{URL: params.URL, Error: fmt.Errorf("invalid URL: %w", err)}
+		return Result{URL: params.URL, Error: err}
 	}
 
-	start := time.Now()
-	resp, err := client.Do(req)
-	duration := time.Since(start)
+	traceCtx, trace := withTrace(ctx)
+	req, err := newRequest(traceCtx, params)
+	if err != nil {
+		return Result{URL: params.URL, Error: err}
+	}
+
+	var redirects *redirectPolicy
+	if params.FollowRedirects {
+		redirects = newRedirectPolicy(params)
+		following := *client
+		following.CheckRedirect = redirects.checkRedirect
+		client = &following
+	}
 
+	resp, err := client.Do(req)
 	if err != nil {
+		timing := trace.timing()
 		isTimeout := ctx.Err() == context.DeadlineExceeded ||
 			strings.Contains(err.Error(), "timeout") ||
 			strings.Contains(err.Error(), "deadline exceeded")
 		return Result{
 			URL:       params.URL,
 			Error:     fmt.Errorf("request failed: %w", err),
-			Duration:  duration,
+			Duration:  timing.Total,
+			Timing:    timing,
 			IsTimeout: isTimeout,
 		}
 	}
@@ -82,39 +303,63 @@ func fetchURL(ctx context.Context, params CheckParams, timeout time.Duration) Re
 		URL:        params.URL,
 		StatusCode: resp.StatusCode,
 		BodyMatch:  true,
-		Duration:   duration,
+		FinalURL:   resp.Request.URL.String(),
+		Header:     resp.Header,
+	}
+	if redirects != nil {
+		result.Redirects = redirects.redirects()
+		result.RedirectError = redirects.violation
+	}
+
+	for _, assertion := range assertions.headers {
+		if failure := assertion.evaluate(resp.Header, assertions.regexes[assertion.Expected]); failure != "" {
+			result.HeaderFailures = append(result.HeaderFailures, failure)
+		}
+	}
+
+	// Read the body (up to the limit) so that the total time includes the transfer
+	body := newBodyScanner(params, opts.bodyLimit, opts.keepBody)
+	err = body.scan(resp.Body)
+	result.Timing = trace.timing()
+	result.Duration = result.Timing.Total
+	if err != nil {
+		result.Error = fmt.Errorf("failed to read body: %w", err)
+		return result
+	}
+	result.BodySize = body.size
+	result.BodyTruncated = body.truncated
+	result.ContentHash = body.sum()
+	if opts.keepBody {
+		result.Body = body.buf.Bytes()
 	}
 
 	// Check body content if required
-	if params.ExpectedBody != "" {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			result.Error = fmt.Errorf("failed to read body: %w", err)
-			return result
+	if needsBody(params) {
+		if body.contains != nil {
+			result.BodyMatch = body.contains.found
+		}
+		result.BodyFailures = checkBody(body, params, assertions)
+		if len(params.JSONAssertions) > 0 || params.JSONValuePath != "" {
+			result.JSONFailures, result.JSONValue = evaluateJSON(body.buf.Bytes(), assertions, params.JSONValuePath)
 		}
-		result.BodyMatch = strings.Contains(string(body), params.ExpectedBody)
 	}
 
 	return result
 }
 
-// slowThreshold is the duration above which a successful request is considered slow
-const slowThreshold = 2 * time.Second
-
 // buildPayload creates a CheckPayload from the check result
 func buildPayload(cfg Config, params CheckParams, result Result) api.CheckPayload {
 	payload := api.CheckPayload{
 		Dashboard:        cfg.Dashboard,
 		Site:             cfg.Site,
-		Service:          params.URL,
+		Service:          cfg.checkKey(params.URL),
 		Name:             cfg.Name,
 		InactiveExpire:   cfg.InactiveExpire,
 		InactiveEscalate: cfg.InactiveEscalate,
 		Highlighted:      cfg.Highlighted,
+		Measurement:      measurement(cfg, params, result),
 	}
 
-	durationSecs := result.Duration.Seconds()
-
 	// Handle request errors
 	if result.Error != nil {
 		payload.AlertLevel = 2 // alert
@@ -127,48 +372,96 @@ func buildPayload(cfg Config, params CheckParams, result Result) api.CheckPayloa
 		return payload
 	}
 
-	// Check status code
-	codeOK := false
-	if params.ExpectedCode == 0 {
-		// Any 2xx is OK
-		codeOK = result.StatusCode >= 200 && result.StatusCode < 300
-	} else {
-		codeOK = result.StatusCode == params.ExpectedCode
-	}
+	// Check status code (validated in fetchURL)
+	codes, _ := expectedStatus(params)
 
 	// Determine alert level
 	var reasons []string
 
-	if !codeOK {
-		if params.ExpectedCode == 0 {
-			reasons = append(reasons, fmt.Sprintf("expected status 2xx"))
-		} else {
-			reasons = append(reasons, fmt.Sprintf("expected status %d", params.ExpectedCode))
-		}
+	if !codes.Matches(result.StatusCode) {
+		reasons = append(reasons, fmt.Sprintf("expected status %s", codes))
 	}
 
 	if !result.BodyMatch {
 		reasons = append(reasons, fmt.Sprintf("body missing expected string: %q", params.ExpectedBody))
 	}
 
+	if result.RedirectError != "" {
+		reasons = append(reasons, result.RedirectError)
+	}
+	if params.ExpectedFinalURL != "" && !strings.HasPrefix(result.FinalURL, params.ExpectedFinalURL) {
+		reasons = append(reasons, fmt.Sprintf("expected final URL %s, got %s", params.ExpectedFinalURL, result.FinalURL))
+	}
+
+	reasons = append(reasons, bodySizeReasons(params, result, cfg.bodyLimit())...)
+	reasons = append(reasons, result.BodyFailures...)
+	reasons = append(reasons, result.HeaderFailures...)
+	reasons = append(reasons, result.JSONFailures...)
+
 	if len(reasons) > 0 {
 		payload.AlertLevel = 2 // alert
-		payload.Value = fmt.Sprintf("%d", result.StatusCode)
-		payload.Message = appendAlertReason(cfg.Message, strings.Join(reasons, "; "))
-	} else if result.Duration > slowThreshold {
+	} else if reasons = cfg.SlowThresholds.Exceeded(result.Timing); len(reasons) > 0 {
 		// Successful but slow response - warning level
 		payload.AlertLevel = 1 // warning
-		payload.Value = fmt.Sprintf("%d", result.StatusCode)
-		payload.Message = appendAlertReason(cfg.Message, fmt.Sprintf("slow response: %.2fs", durationSecs))
 	} else {
 		payload.AlertLevel = 0 // ok
-		payload.Value = fmt.Sprintf("%d", result.StatusCode)
-		payload.Message = cfg.Message
+	}
+
+	// Only record content from healthy responses so that an error page doesn't become the baseline
+	if cfg.Baseline != nil && payload.AlertLevel < 2 && !result.BodyTruncated {
+		if change := cfg.Baseline.observe(cfg.checkKey(params.URL), result.ContentHash, result.BodySize); change != "" {
+			payload.AlertLevel = 1 // warning
+			reasons = append(reasons, change)
+		}
+	}
+
+	payload.Value = fmt.Sprintf("%d", result.StatusCode)
+	if result.Redirects != "" {
+		reasons = append(reasons, result.Redirects)
+	}
+	if payload.AlertLevel > 0 {
+		// The measurement carries the timing of every check; messages only show it
+		// to explain a problem, so an OK check's message stays the same
+		reasons = append(reasons, result.Timing.String())
+	}
+	payload.Message = appendAlertReason(cfg.Message, strings.Join(reasons, "; "))
+
+	if result.JSONValue != "" {
+		payload.Value = result.JSONValue
 	}
 
 	return payload
 }
 
+// measurement returns the data behind a URL check for message and value templates
+func measurement(cfg Config, params CheckParams, result Result) *api.Measurement {
+	total := cfg.SlowThresholds.Total
+	if total == 0 {
+		total = DefaultSlowThreshold
+	}
+
+	return &api.Measurement{
+		Raw: result.Timing.Total,
+		Values: map[string]any{
+			"status":     result.StatusCode,
+			"body_bytes": result.BodySize,
+			"final_url":  result.FinalURL,
+			"dns":        result.Timing.DNS,
+			"connect":    result.Timing.Connect,
+			"tls":        result.Timing.TLS,
+			"ttfb":       result.Timing.TTFB,
+		},
+		Thresholds: map[string]any{
+			"slow":         total,
+			"slow_dns":     cfg.SlowThresholds.DNS,
+			"slow_connect": cfg.SlowThresholds.Connect,
+			"slow_tls":     cfg.SlowThresholds.TLS,
+			"slow_ttfb":    cfg.SlowThresholds.TTFB,
+		},
+		Target: params.URL,
+	}
+}
+
 // appendAlertReason appends an alert reason to an existing message
 func appendAlertReason(message, reason string) string {
 	if message == "" {
@@ -2,10 +2,17 @@ package urlcheck
 
 import (
 	"context"
+	"encoding/pem"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/alertbingo/alertbingo/tlsconfig"
 )
 
 func TestCheck_Success(t *testing.T) {
@@ -148,3 +155,153 @@ func TestCheck_ConnectionError(t *testing.T) {
 		t.Errorf("expected Value 'Error', got %s", result.Value)
 	}
 }
+
+func TestCheck_TLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		opts      tlsconfig.Options
+		wantLevel int
+	}{
+		{"system roots", tlsconfig.Options{}, 2},
+		{"custom CA", tlsconfig.Options{CAFile: caFile}, 0},
+		{"insecure skip verify", tlsconfig.Options{InsecureSkipVerify: true}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := tt.opts.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := Config{
+				Dashboard: "test",
+				Site:      "test-site",
+				Timeout:   5 * time.Second,
+				TLSConfig: tlsConfig,
+			}
+
+			result := Check(context.Background(), cfg, CheckParams{URL: server.URL})
+			if result.AlertLevel != tt.wantLevel {
+				t.Errorf("expected AlertLevel %d, got %d (%s)", tt.wantLevel, result.AlertLevel, result.Message)
+			}
+		})
+	}
+}
+
+func TestCheckAll(t *testing.T) {
+	var mu sync.Mutex
+	active, maxActive := 0, 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		maxActive = max(maxActive, active)
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := Config{
+		Dashboard: "test",
+		Site:      "test-site",
+		Timeout:   5 * time.Second,
+	}
+
+	var targets []CheckParams
+	for i := 0; i < 6; i++ {
+		targets = append(targets, CheckParams{URL: server.URL + "/ok"})
+	}
+	targets = append(targets, CheckParams{URL: server.URL + "/missing"})
+
+	checks := CheckAll(context.Background(), cfg, targets, 2)
+
+	if len(checks) != len(targets) {
+		t.Fatalf("expected %d checks, got %d", len(targets), len(checks))
+	}
+	for i, check := range checks {
+		if check.Service != targets[i].URL {
+			t.Errorf("checks[%d].Service = %q, want %q", i, check.Service, targets[i].URL)
+		}
+	}
+	if checks[len(checks)-1].AlertLevel != 2 {
+		t.Errorf("expected AlertLevel 2 for missing page, got %d", checks[len(checks)-1].AlertLevel)
+	}
+	if maxActive > 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", maxActive)
+	}
+}
+
+func TestCheck_Attempts(t *testing.T) {
+	var mu sync.Mutex
+	var requests, failFirst int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests <= failFirst {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	cfg := Config{Dashboard: "test", Site: "test-site", Message: "API", Timeout: 5 * time.Second, Attempts: 3, AttemptDelay: time.Millisecond}
+
+	tests := []struct {
+		name         string
+		failFirst    int
+		wantLevel    int
+		wantRequests int
+		wantMessage  string
+	}{
+		{"first attempt succeeds", 0, 0, 1, "API - "},
+		{"retry succeeds", 2, 1, 3, "API - 2 of 3 attempts failed (attempt 1: expected status 2xx; "},
+		{"all attempts fail", 5, 2, 3, "API - all 3 attempts failed: attempt 1: expected status 2xx; "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			requests, failFirst = 0, tt.failFirst
+			mu.Unlock()
+
+			result := Check(context.Background(), cfg, CheckParams{URL: server.URL})
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if result.AlertLevel != tt.wantLevel {
+				t.Errorf("expected AlertLevel %d, got %d (%s)", tt.wantLevel, result.AlertLevel, result.Message)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("expected %d requests, got %d", tt.wantRequests, requests)
+			}
+			if !strings.HasPrefix(result.Message, tt.wantMessage) {
+				t.Errorf("expected message to start with %q, got %q", tt.wantMessage, result.Message)
+			}
+			if tt.failFirst == 0 && strings.Contains(result.Message, "attempt") {
+				t.Errorf("expected no attempts in message, got %q", result.Message)
+			}
+		})
+	}
+}